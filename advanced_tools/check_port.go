@@ -2,28 +2,45 @@ package advanced_tools
 
 import (
 	"fmt"
+	"io"
 	"net"
-	"strings"
 	"time"
+
+	"github.com/fatih/color"
 )
 
-// CheckPort 检查远程服务器端口是否开放，hostPort格式为"host:port"
-func CheckPort(hostPort string, timeoutSec int) bool {
-	parts := strings.Split(hostPort, ":")
-	if len(parts) != 2 {
-		fmt.Println("输入格式错误，应为 host:port")
+// CheckPort 检查远程服务器端口是否开放并把检查过程写到out，hostPort格式为"host:port"，IPv6地址格式为"[::1]:9092"
+func CheckPort(hostPort string, timeout time.Duration, out io.Writer) bool {
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		fmt.Fprintf(out, "输入格式错误，应为 host:port 或 [IPv6]:port: %s\n", hostPort)
 		return false
 	}
-	timeout := time.Duration(timeoutSec) * time.Second
-	fmt.Printf("正在检查端口 %s 是否开放...\n", hostPort)
+	fmt.Fprintf(out, "正在检查端口 %s 是否开放...\n", hostPort)
 	conn, err := net.DialTimeout("tcp", hostPort, timeout)
 	if err != nil {
-		fmt.Printf("端口 %s 未开放\n", hostPort)
-		fmt.Println(err)
+		fmt.Fprintf(out, "端口 %s 未开放\n", hostPort)
+		fmt.Fprintln(out, err)
 		return false
 	} else {
-		fmt.Printf("端口 %s 已开放\n", hostPort)
+		fmt.Fprintf(out, "端口 %s 已开放\n", hostPort)
 	}
 	defer conn.Close()
 	return true
 }
+
+// CheckPorts 逐个检查bootstrap地址，返回端口开放的地址，不可达的地址单独报告
+func CheckPorts(hostPorts []string, timeout time.Duration, out io.Writer) []string {
+	var reachable []string
+	for _, hostPort := range hostPorts {
+		if CheckPort(hostPort, timeout, out) {
+			color.Green("✔%s 端口已开放", hostPort)
+			reachable = append(reachable, hostPort)
+		} else {
+			color.Red("✘%s 端口未开放或连接失败", hostPort)
+		}
+	}
+	if len(reachable) > 0 && len(reachable) < len(hostPorts) {
+		color.Yellow("%d/%d 个bootstrap地址可用，将跳过不可达的地址继续连接", len(reachable), len(hostPorts))
+	}
+	return reachable
+}
@@ -4,16 +4,26 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"kafka_dog/cluster_tools"
+
 	"github.com/manifoldco/promptui"
+	"golang.org/x/term"
 )
 
-func InputInCmd(host *string, sha256Enabled, sha512Enabled *bool, username, password *string, listTopics, topicDetail,
-	listConsumerGroups, consumerGroupsDetail *bool, topicKeyword, groupKeyword *string, testConsumeFromLatest *bool,
+func InputInCmd(host *string, profileNames []string, profileName *string, sha256Enabled, sha512Enabled, plainEnabled, oauthEnabled *bool, username, password,
+	oauthTokenFile, oauthTokenCmd *string, tlsOpts *cluster_tools.TLSOptions, listTopics, topicDetail,
+	listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigs, topicAlterConfig, topicAddPartitions, health, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups *bool, topicKeyword, groupKeyword *string, testConsumeFromLatest *bool,
 	groupTopicKeyword, topicName, groupName *string) {
+	if len(profileNames) > 0 && InputInCmdProfile(profileNames, profileName) {
+		ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigs, topicAlterConfig, topicAddPartitions, health, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups, topicKeyword, groupKeyword, groupTopicKeyword, testConsumeFromLatest, topicName, groupName)
+		return
+	}
+
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("请输入Kafka Broker地址，格式为 ip:port:")
+	fmt.Printf("请输入Kafka Broker地址，格式为 ip:port，多个地址用逗号分隔:")
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -22,11 +32,11 @@ func InputInCmd(host *string, sha256Enabled, sha512Enabled *bool, username, pass
 	}
 	*host = input
 
-	sslTypesChoices := []string{"SHA-256", "SHA-512", "PLAINTEXT"}
+	sslTypesChoices := []string{"SHA-256", "SHA-512", "SASL/PLAIN", "OAUTHBEARER", "PLAINTEXT"}
 	prompt := promptui.Select{
 		Label: "请选择一个选项",
 		Items: sslTypesChoices,
-		Size:  3,
+		Size:  5,
 		Templates: &promptui.SelectTemplates{
 			Active:   `{{ "▸" | cyan }} {{ . | cyan }}`,
 			Inactive: `  {{ . }}`,
@@ -47,26 +57,185 @@ func InputInCmd(host *string, sha256Enabled, sha512Enabled *bool, username, pass
 	} else if result == "SHA-512" {
 		*sha512Enabled = true
 		InputInCmdAuth(username, password)
+	} else if result == "SASL/PLAIN" {
+		*plainEnabled = true
+		InputInCmdAuth(username, password)
+	} else if result == "OAUTHBEARER" {
+		*oauthEnabled = true
+		InputInCmdOAuth(oauthTokenFile, oauthTokenCmd)
 	} else {
 		*sha256Enabled = false
 		*sha512Enabled = false
+		*plainEnabled = false
+		*oauthEnabled = false
 	}
 
-	ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail, topicKeyword, groupKeyword, groupTopicKeyword, testConsumeFromLatest, topicName, groupName)
+	if !tlsOpts.Active() {
+		InputInCmdTLS(tlsOpts)
+	}
+
+	ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigs, topicAlterConfig, topicAddPartitions, health, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups, topicKeyword, groupKeyword, groupTopicKeyword, testConsumeFromLatest, topicName, groupName)
 }
 
 func InputInCmdAuth(username, password *string) {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("请输入Kafka用户名:")
-	inputUsername, _ := reader.ReadString('\n')
-	*username = strings.TrimSpace(inputUsername)
+	// 已通过环境变量提供用户名或密码时不再询问，ResolveCredentials会读取环境变量
+	if *username == "" && os.Getenv(cluster_tools.EnvUsername) == "" {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Printf("请输入Kafka用户名:")
+		inputUsername, _ := reader.ReadString('\n')
+		*username = strings.TrimSpace(inputUsername)
+	}
+
+	if *password == "" && os.Getenv(cluster_tools.EnvPassword) == "" {
+		InputPassword(password)
+	}
+}
 
-	reader2 := bufio.NewReader(os.Stdin)
+// InputPassword 不回显地读取密码，标准输入不是终端时按普通输入读取
+func InputPassword(password *string) {
 	fmt.Printf("请输入Kafka密码:")
-	inputPassword, _ := reader2.ReadString('\n')
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		inputPassword, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err == nil {
+			*password = strings.TrimSpace(string(inputPassword))
+			return
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	inputPassword, _ := reader.ReadString('\n')
 	*password = strings.TrimSpace(inputPassword)
 }
 
+// InputInCmdProfile 选择配置文件中保存的集群，选择手动输入时返回false
+func InputInCmdProfile(profileNames []string, profileName *string) bool {
+	manualInput := "手动输入Kafka地址"
+	profileChoices := append([]string{manualInput}, profileNames...)
+	prompt := promptui.Select{
+		Label: "请选择要连接的集群",
+		Items: profileChoices,
+		Size:  10,
+		Templates: &promptui.SelectTemplates{
+			Active:   `{{ "▸" | cyan }} {{ . | cyan }}`,
+			Inactive: `  {{ . }}`,
+			Selected: `{{ "✔" | green }} {{ . | green }}`,
+		},
+		Stdout: os.Stderr, // 避免在某些终端卡住
+	}
+
+	_, result, err := prompt.Run()
+	if err != nil {
+		fmt.Printf("选择失败: %v\n", err)
+		return false
+	}
+	if result == manualInput {
+		return false
+	}
+	*profileName = result
+	return true
+}
+
+// InputInCmdOAuth 输入OAUTHBEARER token文件路径或获取token的命令
+func InputInCmdOAuth(oauthTokenFile, oauthTokenCmd *string) {
+	if *oauthTokenFile != "" || *oauthTokenCmd != "" {
+		return
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("请输入OAUTHBEARER token文件路径(留空则输入获取token的命令):")
+	inputFile, _ := reader.ReadString('\n')
+	*oauthTokenFile = strings.TrimSpace(inputFile)
+	if *oauthTokenFile != "" {
+		return
+	}
+
+	fmt.Printf("请输入获取token的命令(命令的标准输出即为token):")
+	inputCmd, _ := reader.ReadString('\n')
+	*oauthTokenCmd = strings.TrimSpace(inputCmd)
+}
+
+// InputInCmdTLS 交互式选择是否启用TLS，并输入CA、客户端证书和私钥路径
+func InputInCmdTLS(tlsOpts *cluster_tools.TLSOptions) {
+	tlsChoices := []string{"不启用TLS", "TLS", "双向TLS(mTLS)"}
+	prompt := promptui.Select{
+		Label: "是否启用TLS",
+		Items: tlsChoices,
+		Size:  3,
+		Templates: &promptui.SelectTemplates{
+			Active:   `{{ "▸" | cyan }} {{ . | cyan }}`,
+			Inactive: `  {{ . }}`,
+			Selected: `{{ "✔" | green }} {{ . | green }}`,
+		},
+		Stdout: os.Stderr, // 避免在某些终端卡住
+	}
+
+	_, result, err := prompt.Run()
+	if err != nil {
+		fmt.Printf("选择失败: %v\n", err)
+		return
+	}
+	if result == "不启用TLS" {
+		return
+	}
+	tlsOpts.Enabled = true
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("请输入CA证书路径(留空使用系统证书):")
+	inputCA, _ := reader.ReadString('\n')
+	tlsOpts.CAFile = strings.TrimSpace(inputCA)
+
+	if result == "双向TLS(mTLS)" {
+		fmt.Printf("请输入客户端证书路径:")
+		inputCert, _ := reader.ReadString('\n')
+		tlsOpts.CertFile = strings.TrimSpace(inputCert)
+
+		fmt.Printf("请输入客户端私钥路径:")
+		inputKey, _ := reader.ReadString('\n')
+		tlsOpts.KeyFile = strings.TrimSpace(inputKey)
+	}
+
+	fmt.Printf("请输入证书校验使用的服务器名称(留空使用连接地址):")
+	inputServerName, _ := reader.ReadString('\n')
+	tlsOpts.ServerName = strings.TrimSpace(inputServerName)
+
+	fmt.Printf("是否跳过服务器证书校验(y/N):")
+	inputInsecure, _ := reader.ReadString('\n')
+	inputInsecure = strings.ToLower(strings.TrimSpace(inputInsecure))
+	tlsOpts.InsecureSkipVerify = inputInsecure == "y" || inputInsecure == "yes"
+}
+
+// InputTopicCreate 交互式输入创建topic所需的名称、分区数、副本数和配置
+func InputTopicCreate(topicName *string, partitions, replicationFactor *int, topicConfigs *string, validateOnly *bool) {
+	reader := bufio.NewReader(os.Stdin)
+	for *topicName == "" {
+		fmt.Printf("请输入要创建的Topic名称:")
+		inputTopicName, _ := reader.ReadString('\n')
+		*topicName = strings.TrimSpace(inputTopicName)
+	}
+
+	fmt.Printf("请输入分区数(留空使用broker默认值):")
+	inputPartitions, _ := reader.ReadString('\n')
+	if n, err := strconv.Atoi(strings.TrimSpace(inputPartitions)); err == nil && n > 0 {
+		*partitions = n
+	}
+
+	fmt.Printf("请输入副本数(留空使用broker默认值):")
+	inputReplicationFactor, _ := reader.ReadString('\n')
+	if n, err := strconv.Atoi(strings.TrimSpace(inputReplicationFactor)); err == nil && n > 0 {
+		*replicationFactor = n
+	}
+
+	fmt.Printf("请输入Topic配置，格式为 key=value,key=value(留空不设置):")
+	inputConfigs, _ := reader.ReadString('\n')
+	*topicConfigs = strings.TrimSpace(inputConfigs)
+
+	fmt.Printf("是否只校验不创建(y/N):")
+	inputValidateOnly, _ := reader.ReadString('\n')
+	inputValidateOnly = strings.ToLower(strings.TrimSpace(inputValidateOnly))
+	*validateOnly = inputValidateOnly == "y" || inputValidateOnly == "yes"
+}
+
 func InputKeywords(keywords_type, topicKeyword, groupKeyword, groupTopicKeyword *string, listConsumerGroups *bool) {
 	if *keywords_type == "topic" {
 		reader := bufio.NewReader(os.Stdin)
@@ -93,13 +262,13 @@ func InputKeywords(keywords_type, topicKeyword, groupKeyword, groupTopicKeyword
 	}
 }
 
-func ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail *bool, topicKeyword, groupKeyword, groupTopicKeyword *string, testConsumeFromLatest *bool,
+func ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigs, topicAlterConfig, topicAddPartitions, health, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups *bool, topicKeyword, groupKeyword, groupTopicKeyword *string, testConsumeFromLatest *bool,
 	topicName, groupName *string) {
-	opsChoices := []string{"检查连接情况", "查看Topic列表", "查看Topic详情", "查看Consumer Group列表", "查看Consumer Group详情", "测试消费Topic"}
+	opsChoices := []string{"检查连接情况", "诊断Broker广播地址", "查看Broker列表", "查看Broker配置", "修改Broker配置", "查看磁盘占用", "集群健康检查", "Leader均衡报告", "触发Leader选举", "查看Topic列表", "查看Topic详情", "查看消费Topic的Consumer Group", "查看Topic配置", "修改Topic配置", "增加Topic分区", "创建Topic", "删除Topic", "生成分区重分配计划", "执行分区重分配", "查看分区重分配进度", "取消分区重分配", "查看Consumer Group列表", "查看Consumer Group详情", "重置Consumer Group Offset", "删除Consumer Group", "删除Consumer Group的Topic Offset", "测试消费Topic"}
 	prompt := promptui.Select{
 		Label: "请选择一个选项",
 		Items: opsChoices,
-		Size:  6,
+		Size:  10,
 		Templates: &promptui.SelectTemplates{
 			Active:   `{{ "▸" | cyan }} {{ . | cyan }}`,
 			Inactive: `  {{ . }}`,
@@ -117,6 +286,34 @@ func ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail
 	switch result {
 	case "检查连接情况":
 		// 什么都不做，直接返回
+	case "诊断Broker广播地址":
+		*diagnose = true
+	case "查看Broker列表":
+		*brokerList = true
+	case "查看Broker配置":
+		*brokerConfigs = true
+	case "修改Broker配置":
+		*brokerAlterConfig = true
+	case "查看磁盘占用":
+		*logDirs = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "集群健康检查":
+		*health = true
+	case "Leader均衡报告":
+		*leaderReport = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "触发Leader选举":
+		*electLeaders = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
 	case "查看Topic列表":
 		*listTopics = true
 		if *topicName == "" {
@@ -129,12 +326,66 @@ func ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail
 			keywords_type := "topic"
 			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
 		}
+	case "查看消费Topic的Consumer Group":
+		*topicGroups = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "查看Topic配置":
+		*topicConfigs = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "修改Topic配置":
+		*topicAlterConfig = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "增加Topic分区":
+		*topicAddPartitions = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "创建Topic":
+		*topicCreate = true
+	case "生成分区重分配计划":
+		*reassignGenerate = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
+	case "执行分区重分配":
+		*reassignExecute = true
+	case "查看分区重分配进度":
+		*reassignStatus = true
+	case "取消分区重分配":
+		*reassignCancel = true
+	case "删除Topic":
+		*topicDelete = true
+		if *topicName == "" {
+			keywords_type := "topic"
+			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
+		}
 	case "查看Consumer Group列表":
 		*listConsumerGroups = true
 		if *groupName == "" {
 			keywords_type := "group"
 			InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
 		}
+	case "重置Consumer Group Offset":
+		*groupResetOffsets = true
+	case "删除Consumer Group":
+		*groupDelete = true
+		if *groupName == "" {
+			InputDeleteGroupKeyword(groupKeyword)
+		}
+	case "删除Consumer Group的Topic Offset":
+		*groupDeleteOffsets = true
+		InputGroupTopic(groupName, groupKeyword, topicName)
 	case "查看Consumer Group详情":
 		*consumerGroupsDetail = true
 		if *groupName == "" {
@@ -147,3 +398,204 @@ func ChoseOps(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail
 		InputKeywords(&keywords_type, topicKeyword, groupKeyword, groupTopicKeyword, listConsumerGroups)
 	}
 }
+
+// InputPartitionCount 交互式输入扩容后的分区总数
+func InputPartitionCount(partitions *int) {
+	reader := bufio.NewReader(os.Stdin)
+	for *partitions <= 0 {
+		fmt.Printf("请输入扩容后的分区总数:")
+		input, _ := reader.ReadString('\n')
+		if n, err := strconv.Atoi(strings.TrimSpace(input)); err == nil && n > 0 {
+			*partitions = n
+		}
+	}
+}
+
+// InputConfigChanges 交互式输入要设置、删除和追加的配置项
+func InputConfigChanges(configSet, configDelete, configAppend *string) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("请输入要设置的配置，格式为 key=value,key=value(留空跳过):")
+	inputSet, _ := reader.ReadString('\n')
+	*configSet = strings.TrimSpace(inputSet)
+
+	fmt.Printf("请输入要删除(恢复默认)的配置名，多个用逗号分隔(留空跳过):")
+	inputDelete, _ := reader.ReadString('\n')
+	*configDelete = strings.TrimSpace(inputDelete)
+
+	fmt.Printf("请输入要追加值的列表配置，格式为 key=value(留空跳过):")
+	inputAppend, _ := reader.ReadString('\n')
+	*configAppend = strings.TrimSpace(inputAppend)
+}
+
+// InputReassign 交互式输入重分配的目标broker、计划文件路径和限流速率
+func InputReassign(generate, execute bool, targetBrokers, reassignFile *string, throttle *int) {
+	reader := bufio.NewReader(os.Stdin)
+	for generate && *targetBrokers == "" {
+		fmt.Printf("请输入目标broker ID，多个用逗号分隔:")
+		input, _ := reader.ReadString('\n')
+		*targetBrokers = strings.TrimSpace(input)
+	}
+
+	fmt.Printf("请输入重分配计划文件路径(留空使用%s):", *reassignFile)
+	inputFile, _ := reader.ReadString('\n')
+	if inputFile = strings.TrimSpace(inputFile); inputFile != "" {
+		*reassignFile = inputFile
+	}
+
+	if execute {
+		fmt.Printf("请输入副本同步限流速率，单位字节/秒(留空不限流):")
+		inputThrottle, _ := reader.ReadString('\n')
+		if n, err := strconv.Atoi(strings.TrimSpace(inputThrottle)); err == nil && n > 0 {
+			*throttle = n
+		}
+	}
+}
+
+// InputBrokerID 交互式输入要查看或修改配置的broker ID
+func InputBrokerID(brokerID *string) {
+	reader := bufio.NewReader(os.Stdin)
+	for *brokerID == "" {
+		fmt.Printf("请输入broker ID(输入default表示集群默认配置):")
+		input, _ := reader.ReadString('\n')
+		*brokerID = strings.TrimSpace(input)
+	}
+}
+
+// InputGroupDetailMode 交互式选择消费组详情的查看方式：持续刷新、按时间计算lag或只查看一次
+func InputGroupDetailMode(watch, timeLag *bool, interval *int) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("是否持续刷新消费组详情(y/N):")
+	input, _ := reader.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer == "y" || answer == "yes" {
+		*watch = true
+	} else {
+		fmt.Printf("是否显示按时间计算的lag和预计追上时间(y/N):")
+		input, _ = reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+			return
+		}
+		*timeLag = true
+	}
+
+	label := "刷新间隔"
+	if *timeLag {
+		label = "速率采样窗口"
+	}
+	for {
+		fmt.Printf("请输入%s(秒，默认%d):", label, *interval)
+		input, _ = reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return
+		}
+		if n, err := strconv.Atoi(input); err == nil && n > 0 {
+			*interval = n
+			return
+		}
+		fmt.Println(label + "必须是正整数")
+	}
+}
+
+// InputOffsetReset 交互式输入重置offset的消费组、topic范围、策略和参数
+func InputOffsetReset(groupName, groupKeyword, topicName, resetPartitions, resetStrategy, resetValue *string) {
+	reader := bufio.NewReader(os.Stdin)
+	if *groupName == "" {
+		fmt.Printf("请输入要重置的Consumer Group关键词:")
+		input, _ := reader.ReadString('\n')
+		*groupKeyword = strings.TrimSpace(input)
+	}
+
+	strategies := []string{"earliest", "latest", "datetime", "shift", "offset", "file"}
+	if *resetStrategy == "" {
+		prompt := promptui.Select{
+			Label: "请选择重置策略",
+			Items: strategies,
+			Size:  6,
+			Templates: &promptui.SelectTemplates{
+				Active:   `{{ "▸" | cyan }} {{ . | cyan }}`,
+				Inactive: `  {{ . }}`,
+				Selected: `{{ "✔" | green }} {{ . | green }}`,
+			},
+			Stdout: os.Stderr, // 避免在某些终端卡住
+		}
+		_, result, err := prompt.Run()
+		if err != nil {
+			fmt.Printf("选择失败: %v\n", err)
+			return
+		}
+		*resetStrategy = result
+	}
+
+	labels := map[string]string{
+		"datetime": "请输入时间，如2006-01-02 15:04:05:",
+		"shift":    "请输入偏移的消息数，负数表示往回:",
+		"offset":   "请输入目标offset:",
+		"file":     "请输入offset文件路径(每行topic,partition,offset):",
+	}
+	if label, ok := labels[*resetStrategy]; ok {
+		for *resetValue == "" {
+			fmt.Print(label)
+			input, _ := reader.ReadString('\n')
+			*resetValue = strings.TrimSpace(input)
+		}
+	}
+	if *resetStrategy == "file" {
+		return
+	}
+
+	if *topicName == "" {
+		fmt.Printf("请输入要重置的Topic名称(留空重置所有已提交过offset的topic):")
+		input, _ := reader.ReadString('\n')
+		*topicName = strings.TrimSpace(input)
+	}
+	if *topicName != "" && *resetPartitions == "" {
+		fmt.Printf("请输入要重置的分区，多个用逗号分隔(留空重置所有分区):")
+		input, _ := reader.ReadString('\n')
+		*resetPartitions = strings.TrimSpace(input)
+	}
+}
+
+// InputGroupTopic 交互式输入消费组关键词和topic名称
+func InputGroupTopic(groupName, groupKeyword, topicName *string) {
+	reader := bufio.NewReader(os.Stdin)
+	if *groupName == "" {
+		fmt.Printf("请输入Consumer Group关键词:")
+		input, _ := reader.ReadString('\n')
+		*groupKeyword = strings.TrimSpace(input)
+	}
+	for *topicName == "" {
+		fmt.Printf("请输入Topic名称:")
+		input, _ := reader.ReadString('\n')
+		*topicName = strings.TrimSpace(input)
+	}
+}
+
+// InputDeleteGroupKeyword 交互式输入要删除的消费组关键词，删除前会预览匹配的消费组并要求确认
+func InputDeleteGroupKeyword(groupKeyword *string) {
+	reader := bufio.NewReader(os.Stdin)
+	for *groupKeyword == "" {
+		fmt.Printf("请输入要删除的Consumer Group关键词:")
+		input, _ := reader.ReadString('\n')
+		*groupKeyword = strings.TrimSpace(input)
+	}
+}
+
+// ConfirmYesNo 询问是否继续，标准输入不是终端时(如脚本中)不询问，直接返回false
+func ConfirmYesNo(message string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s(y/N):", message)
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+// ConfirmTyped 要求用户完整输入expected才继续，用于删除等不可恢复的操作
+func ConfirmTyped(message, expected string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s，请输入 %s 确认:", message, expected)
+	input, _ := reader.ReadString('\n')
+	return strings.TrimSpace(input) == expected
+}
@@ -0,0 +1,197 @@
+package advanced_tools
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+const (
+	stepOK      = "OK"
+	stepFailed  = "FAIL"
+	stepSkipped = "-"
+)
+
+// endpointResult 单个broker地址各个连接步骤的检查结果
+type endpointResult struct {
+	id      int32
+	addr    string
+	steps   map[string]string
+	failure string
+}
+
+var diagnoseSteps = []string{"DNS", "TCP", "TLS", "SASL", "API"}
+
+// Diagnose 先通过bootstrap地址获取元数据，再对每个broker广播(advertised)的地址逐步检查
+// DNS解析、TCP连通、TLS握手、SASL认证和API请求，报告具体在哪个broker的哪一步失败，全部通过返回true
+func Diagnose(opts cluster_tools.Options) bool {
+	config, err := cluster_tools.NewConfig(opts)
+	if err != nil {
+		cluster_tools.PrintError("生成连接配置失败: %v", err)
+		return false
+	}
+
+	fmt.Println("检查bootstrap地址:")
+	var bootstrapResults []endpointResult
+	var metadata *sarama.MetadataResponse
+	for _, addr := range opts.Brokers {
+		result, broker := checkEndpoint(-1, addr, config)
+		bootstrapResults = append(bootstrapResults, result)
+		if broker == nil {
+			continue
+		}
+		if metadata == nil {
+			metadata, err = broker.GetMetadata(sarama.NewMetadataRequest(config.Version, nil))
+			if err != nil {
+				result.failure = fmt.Sprintf("获取元数据失败: %v", cluster_tools.MaskError(err))
+				bootstrapResults[len(bootstrapResults)-1] = result
+				metadata = nil
+			}
+		}
+		broker.Close()
+	}
+	allOK := printEndpointResults(bootstrapResults)
+
+	if metadata == nil {
+		color.Red("所有bootstrap地址都无法获取元数据，无法继续检查broker广播的地址")
+		return false
+	}
+
+	fmt.Println("检查broker广播(advertised.listeners)的地址:")
+	brokers := metadata.Brokers
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i].ID() < brokers[j].ID() })
+	var advertisedResults []endpointResult
+	for _, b := range brokers {
+		result, broker := checkEndpoint(b.ID(), b.Addr(), config)
+		if broker != nil {
+			broker.Close()
+		}
+		advertisedResults = append(advertisedResults, result)
+	}
+	if !printEndpointResults(advertisedResults) {
+		allOK = false
+		color.Yellow("bootstrap地址可用但广播地址不可达时，请检查broker的advertised.listeners配置是否为客户端可以解析和访问的地址")
+	}
+
+	if allOK {
+		color.Green("✔所有broker地址检查通过")
+	}
+	return allOK
+}
+
+// checkEndpoint 依次检查一个地址，任一步失败后跳过后续步骤，成功时返回已认证的broker连接
+func checkEndpoint(id int32, addr string, config *sarama.Config) (endpointResult, *sarama.Broker) {
+	result := endpointResult{id: id, addr: addr, steps: map[string]string{}}
+	for _, step := range diagnoseSteps {
+		result.steps[step] = stepSkipped
+	}
+	fail := func(step string, err error) (endpointResult, *sarama.Broker) {
+		result.steps[step] = stepFailed
+		result.failure = fmt.Sprintf("%s: %v", step, cluster_tools.MaskError(err))
+		return result, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fail("DNS", err)
+	}
+	if _, err := net.LookupHost(host); err != nil {
+		return fail("DNS", err)
+	}
+	result.steps["DNS"] = stepOK
+
+	conn, err := net.DialTimeout("tcp", addr, config.Net.DialTimeout)
+	if err != nil {
+		return fail("TCP", err)
+	}
+	result.steps["TCP"] = stepOK
+
+	if config.Net.TLS.Enable {
+		tlsConfig := config.Net.TLS.Config.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = host
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		tlsConn.SetDeadline(time.Now().Add(config.Net.DialTimeout))
+		err = tlsConn.Handshake()
+		tlsConn.Close()
+		if err != nil {
+			return fail("TLS", err)
+		}
+		result.steps["TLS"] = stepOK
+	} else {
+		conn.Close()
+	}
+
+	// Open在后台完成连接和SASL认证，Connected等待其结束并返回认证错误，
+	// 未启用SASL时这两步的失败归入API步骤，SASL保持为"-"
+	connectStep := "API"
+	if config.Net.SASL.Enable {
+		connectStep = "SASL"
+	}
+	broker := sarama.NewBroker(addr)
+	if err := broker.Open(config); err != nil {
+		return fail(connectStep, err)
+	}
+	// 检查失败时关闭连接，成功时由调用方使用完后关闭
+	connected := false
+	defer func() {
+		if !connected {
+			broker.Close()
+		}
+	}()
+	if _, err := broker.Connected(); err != nil {
+		return fail(connectStep, err)
+	}
+	if config.Net.SASL.Enable {
+		result.steps["SASL"] = stepOK
+	}
+	// 发送一个ApiVersions请求确认broker可以正常响应
+	if _, err := broker.ApiVersions(&sarama.ApiVersionsRequest{}); err != nil {
+		return fail("API", err)
+	}
+	result.steps["API"] = stepOK
+	connected = true
+	return result, broker
+}
+
+// printEndpointResults 打印检查结果表格和失败原因，全部通过返回true
+func printEndpointResults(results []endpointResult) bool {
+	headers := append([]string{"BROKER-ID", "ADDRESS"}, diagnoseSteps...)
+	headers = append(headers, "RESULT")
+	var rows [][]string
+	allOK := true
+	for _, r := range results {
+		id := "bootstrap"
+		if r.id >= 0 {
+			id = fmt.Sprintf("%d", r.id)
+		}
+		row := []string{id, r.addr}
+		for _, step := range diagnoseSteps {
+			row = append(row, r.steps[step])
+		}
+		if r.failure == "" {
+			row = append(row, stepOK)
+		} else {
+			row = append(row, stepFailed)
+			allOK = false
+		}
+		rows = append(rows, row)
+	}
+	format_tools.PrintPrettyTable(headers, rows)
+
+	for _, r := range results {
+		if r.failure != "" {
+			color.Red("✘%s 失败于 %s", r.addr, r.failure)
+		}
+	}
+	return allOK
+}
@@ -4,9 +4,9 @@ import (
 	"github.com/fatih/color"
 )
 
-func ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail *bool,
+func ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigs, topicAlterConfig, topicAddPartitions, health, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups *bool,
 	topicKeyword, groupKeyword *string, testConsumeFromBeginning *int, testConsumeFromLatest *bool,
-	sha256Enabled *bool, sha512Enabled *bool, username *string, password, groupTopicKeyword *string) bool {
+	sha256Enabled *bool, sha512Enabled *bool, plainEnabled, oauthEnabled *bool, username *string, password, groupTopicKeyword, groupState *string, watch, timeLag *bool) bool {
 	// 互斥参数检测
 	// topic-list/topic-detail/group-list/group-detail 互斥
 	mainOps := 0
@@ -28,8 +28,34 @@ func ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsD
 	if *testConsumeFromLatest {
 		mainOps++
 	}
+	if *diagnose {
+		mainOps++
+	}
+	if *topicCreate {
+		mainOps++
+	}
+	if *topicDelete {
+		mainOps++
+	}
+	if *topicConfigs {
+		mainOps++
+	}
+	if *topicAlterConfig {
+		mainOps++
+	}
+	if *topicAddPartitions {
+		mainOps++
+	}
+	if *health {
+		mainOps++
+	}
+	for _, op := range []*bool{reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups} {
+		if *op {
+			mainOps++
+		}
+	}
 	if mainOps > 1 {
-		color.Red("参数冲突：-topic-list、-topic-detail、-group-list、-group-detail、-from-beginning、-from-latest 只能选择一个")
+		color.Red("参数冲突：-topic-list、-topic-detail、-topic-create、-topic-delete、-topic-configs、-topic-alter-config、-topic-add-partitions、-topic-groups、-group-list、-group-detail、-group-reset-offsets、-group-delete、-group-delete-offsets、-from-beginning、-from-latest、-diagnose、-broker-list、-broker-configs、-broker-alter-config、-log-dirs、-health、-reassign-generate、-reassign-execute、-reassign-status、-reassign-cancel、-leader-report、-elect-leaders 只能选择一个")
 		return false
 	}
 	// from-beginning/from-latest 互斥
@@ -38,17 +64,39 @@ func ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsD
 		return false
 	}
 
-	if !*listTopics && !*topicDetail && !*testConsumeFromLatest && topicKeyword != nil && *topicKeyword != "" {
-		color.Red("参数错误：-topic-keyword 只能在 -topic-list, -topic-detail 或 -from-latest时使用")
+	if !*listTopics && !*topicDetail && !*testConsumeFromLatest && !*topicDelete && !*topicConfigs && !*topicAlterConfig && !*topicAddPartitions && !*reassignGenerate && !*leaderReport && !*electLeaders && !*logDirs && !*topicGroups && topicKeyword != nil && *topicKeyword != "" {
+		color.Red("参数错误：-topic-keyword 只能在 -topic-list, -topic-detail, -topic-delete, -topic-configs, -topic-alter-config, -topic-add-partitions, -reassign-generate, -leader-report, -elect-leaders, -log-dirs, -topic-groups 或 -from-latest时使用")
 		return false
 	}
 
-	if !*listConsumerGroups && !*consumerGroupsDetail && groupKeyword != nil && *groupKeyword != "" {
-		color.Red("参数错误：-group-keyword 只能在 -group-list 或 -group-detail 时使用")
+	if !*listConsumerGroups && !*consumerGroupsDetail && !*groupResetOffsets && !*groupDelete && !*groupDeleteOffsets && groupKeyword != nil && *groupKeyword != "" {
+		color.Red("参数错误：-group-keyword 只能在 -group-list、-group-detail、-group-reset-offsets、-group-delete 或 -group-delete-offsets 时使用")
+		return false
+	}
+	if *groupState != "" && !*listConsumerGroups {
+		color.Red("参数错误：-group-state 只能与 -group-list 一起使用")
+		return false
+	}
+	if *watch && !*consumerGroupsDetail {
+		color.Red("参数错误：-watch 只能与 -group-detail 一起使用")
+		return false
+	}
+	if *timeLag && !*consumerGroupsDetail {
+		color.Red("参数错误：-time-lag 只能与 -group-detail 一起使用")
 		return false
 	}
-	if *sha256Enabled && *sha512Enabled {
-		color.Red("参数冲突：-sha-256 和 -sha-512 只能选择一个")
+	if *watch && *timeLag {
+		color.Red("参数冲突：-watch 和 -time-lag 只能选择一个")
+		return false
+	}
+	authModes := 0
+	for _, enabled := range []*bool{sha256Enabled, sha512Enabled, plainEnabled, oauthEnabled} {
+		if *enabled {
+			authModes++
+		}
+	}
+	if authModes > 1 {
+		color.Red("参数冲突：-sha-256、-sha-512、-sasl-plain、-oauthbearer 只能选择一个")
 		return false
 	}
 	// if (!*sha256Enabled && !*sha512Enabled) && (*username != "" || *password != "") {
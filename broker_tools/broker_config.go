@@ -0,0 +1,192 @@
+package broker_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"kafka_dog/topic_tools"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// ClusterDefault 表示集群级别的动态默认配置(对所有broker生效)，对应资源名为空的broker配置
+const ClusterDefault = "default"
+
+// brokerResourceName 把broker ID或default转换为配置资源名，集群默认配置的资源名为空
+func brokerResourceName(brokerID string) (string, error) {
+	if brokerID == "" || brokerID == ClusterDefault {
+		return "", nil
+	}
+	if _, err := strconv.ParseInt(brokerID, 10, 32); err != nil {
+		return "", fmt.Errorf("broker ID %q 无效，请输入数字ID或%s", brokerID, ClusterDefault)
+	}
+	return brokerID, nil
+}
+
+// brokerLabel 输出中使用的broker名称
+func brokerLabel(name string) string {
+	if name == "" {
+		return "集群默认配置"
+	}
+	return "broker " + name
+}
+
+// DescribeBrokerConfigs 获取broker的全部生效配置及其同义配置(synonyms)，
+// synonyms按优先级列出该配置在broker动态覆盖、集群动态默认、静态配置和默认值中的取值
+func DescribeBrokerConfigs(cluster cluster_tools.Cluster, brokerID string) ([]sarama.ConfigEntry, error) {
+	name, err := brokerResourceName(brokerID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &sarama.DescribeConfigsRequest{
+		Resources:       []*sarama.ConfigResource{{Type: sarama.BrokerResource, Name: name}},
+		IncludeSynonyms: true,
+	}
+	if cluster.Config().Version.IsAtLeast(sarama.V2_0_0_0) {
+		request.Version = 2
+	} else if cluster.Config().Version.IsAtLeast(sarama.V1_1_0_0) {
+		request.Version = 1
+	}
+
+	// 单个broker的配置必须发给该broker，集群默认配置可以发给任意broker
+	var broker *sarama.Broker
+	if name != "" {
+		id, _ := strconv.ParseInt(name, 10, 32)
+		broker, err = cluster.Client().Broker(int32(id))
+	} else if broker = cluster.Client().LeastLoadedBroker(); broker == nil {
+		err = fmt.Errorf("没有可用的broker")
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = broker.Open(cluster.Config())
+
+	response, err := broker.DescribeConfigs(request)
+	if err != nil {
+		return nil, err
+	}
+	var entries []sarama.ConfigEntry
+	for _, resource := range response.Resources {
+		if resource.ErrorCode != 0 {
+			return nil, fmt.Errorf("%v: %s", sarama.KError(resource.ErrorCode), resource.ErrorMsg)
+		}
+		for _, entry := range resource.Configs {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// formatSynonyms 把同义配置格式化为"来源=值"并按优先级用" > "连接，敏感配置不显示值
+func formatSynonyms(entry sarama.ConfigEntry) string {
+	var parts []string
+	for _, synonym := range entry.Synonyms {
+		value := synonym.ConfigValue
+		if entry.Sensitive {
+			value = "******"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", cluster_tools.ConfigSourceName(synonym.Source), value))
+	}
+	return strings.Join(parts, " > ")
+}
+
+// ShowBrokerConfigs 打印broker配置的值、来源和同义配置，dynamicOnly为true时只显示动态配置
+func ShowBrokerConfigs(cluster cluster_tools.Cluster, brokerID string, dynamicOnly bool) error {
+	name, err := brokerResourceName(brokerID)
+	if err != nil {
+		return err
+	}
+	entries, err := DescribeBrokerConfigs(cluster, brokerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("配置对象:", brokerLabel(name))
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	dynamic := 0
+	for _, entry := range entries {
+		isDynamic := entry.Source == sarama.SourceDynamicBroker || entry.Source == sarama.SourceDynamicDefaultBroker
+		if isDynamic {
+			dynamic++
+		} else if dynamicOnly {
+			continue
+		}
+		if isDynamic {
+			rowColors[len(rows)] = color.New(color.FgYellow)
+		}
+		readOnly := ""
+		if entry.ReadOnly {
+			readOnly = "true"
+		}
+		rows = append(rows, []string{entry.Name, cluster_tools.ConfigValue(entry), cluster_tools.ConfigSourceName(entry.Source), readOnly, formatSynonyms(entry)})
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"NAME", "VALUE", "SOURCE", "READ-ONLY", "SYNONYMS"}, rows, rowColors)
+	fmt.Printf("共 %d 项配置，其中 %d 项为动态配置(黄色)\n", len(entries), dynamic)
+	return nil
+}
+
+// PreviewBrokerConfigChanges 在修改前推算并打印broker配置的变化
+func PreviewBrokerConfigChanges(cluster cluster_tools.Cluster, brokerID string, set map[string]string, deleteKeys []string,
+	appendValues map[string]string) error {
+	name, err := brokerResourceName(brokerID)
+	if err != nil {
+		return err
+	}
+	if len(cluster_tools.IncrementalConfigEntries(set, deleteKeys, appendValues)) == 0 {
+		return fmt.Errorf("没有需要修改的配置项")
+	}
+	before, err := DescribeBrokerConfigs(cluster, brokerID)
+	if err != nil {
+		return err
+	}
+	source := sarama.SourceDynamicBroker
+	if name == "" {
+		source = sarama.SourceDynamicDefaultBroker
+	}
+	fmt.Printf("即将修改%s的配置:\n", brokerLabel(name))
+	topic_tools.PrintConfigDiff(cluster_tools.PlannedConfigChanges(before, set, deleteKeys, appendValues, source))
+	return nil
+}
+
+// AlterBrokerConfigs 通过IncrementalAlterConfigs修改broker或集群默认的动态配置，打印修改前后的差异
+func AlterBrokerConfigs(cluster cluster_tools.Cluster, brokerID string, set map[string]string, deleteKeys []string,
+	appendValues map[string]string, validateOnly bool) error {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_3_0_0, "修改broker配置"); err != nil {
+		return err
+	}
+	name, err := brokerResourceName(brokerID)
+	if err != nil {
+		return err
+	}
+	entries := cluster_tools.IncrementalConfigEntries(set, deleteKeys, appendValues)
+	if len(entries) == 0 {
+		return fmt.Errorf("没有需要修改的配置项")
+	}
+
+	before, err := DescribeBrokerConfigs(cluster, brokerID)
+	if err != nil {
+		return err
+	}
+	if err := cluster.Admin().IncrementalAlterConfig(sarama.BrokerResource, name, entries, validateOnly); err != nil {
+		return err
+	}
+	if validateOnly {
+		color.Green("✔校验通过，%s的配置可以修改(validate-only模式，未实际修改)", brokerLabel(name))
+		return nil
+	}
+
+	after, err := DescribeBrokerConfigs(cluster, brokerID)
+	if err != nil {
+		return err
+	}
+	color.Green("✔修改%s的配置成功", brokerLabel(name))
+	topic_tools.PrintConfigDiff(cluster_tools.DiffConfigs(before, after))
+	return nil
+}
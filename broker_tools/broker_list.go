@@ -0,0 +1,192 @@
+package broker_tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"kafka_dog/topic_tools"
+	"sort"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// 在表格中展示版本范围的API，这几个API的最高版本能大致反映broker的Kafka版本
+const (
+	apiKeyProduce  = 0
+	apiKeyFetch    = 1
+	apiKeyMetadata = 3
+)
+
+// ApiVersion broker支持的一个API的版本范围
+type ApiVersion struct {
+	ApiKey     int16 `json:"api_key"`
+	MinVersion int16 `json:"min_version"`
+	MaxVersion int16 `json:"max_version"`
+}
+
+// BrokerInfo 一个broker的地址、机架、是否为controller、支持的API版本和承担的分区数
+type BrokerInfo struct {
+	ID                  int32        `json:"id"`
+	Address             string       `json:"address"`
+	Rack                string       `json:"rack,omitempty"`
+	Controller          bool         `json:"controller"`
+	AdvertisedListeners string       `json:"advertised_listeners,omitempty"`
+	Partitions          int          `json:"partitions"`
+	Leaders             int          `json:"leaders"`
+	ApiVersions         []ApiVersion `json:"api_versions,omitempty"`
+	// Error 连接broker或获取API版本失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// apiRange 返回apiKey的版本范围，如v0-v13，broker不支持时返回"-"
+func (b BrokerInfo) apiRange(apiKey int16) string {
+	for _, v := range b.ApiVersions {
+		if v.ApiKey == apiKey {
+			return fmt.Sprintf("v%d-v%d", v.MinVersion, v.MaxVersion)
+		}
+	}
+	return "-"
+}
+
+// DescribeBrokers 获取集群中所有broker的信息，单个broker连接失败时记录在Error中，不影响其他broker
+func DescribeBrokers(cluster cluster_tools.Cluster) ([]BrokerInfo, error) {
+	client := cluster.Client()
+	if err := client.RefreshMetadata(); err != nil {
+		return nil, err
+	}
+	controllerID := int32(-1)
+	if controller, err := client.Controller(); err == nil {
+		controllerID = controller.ID()
+	}
+
+	infos, err := topic_tools.DescribePartitions(cluster, nil)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make(map[int32]int)
+	leaders := make(map[int32]int)
+	for _, info := range infos {
+		for _, id := range info.Replicas {
+			partitions[id]++
+		}
+		if !info.Leaderless() {
+			leaders[info.Leader]++
+		}
+	}
+
+	var brokers []BrokerInfo
+	for _, broker := range client.Brokers() {
+		info := BrokerInfo{
+			ID:         broker.ID(),
+			Address:    broker.Addr(),
+			Rack:       broker.Rack(),
+			Controller: broker.ID() == controllerID,
+			Partitions: partitions[broker.ID()],
+			Leaders:    leaders[broker.ID()],
+		}
+		versions, err := brokerApiVersions(cluster, broker)
+		if err != nil {
+			info.Error = cluster_tools.MaskError(err).Error()
+		}
+		info.ApiVersions = versions
+		info.AdvertisedListeners = advertisedListeners(cluster, broker.ID())
+		brokers = append(brokers, info)
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i].ID < brokers[j].ID })
+	return brokers, nil
+}
+
+// brokerApiVersions 向broker发送ApiVersions请求，返回按ApiKey排序的版本范围
+func brokerApiVersions(cluster cluster_tools.Cluster, broker *sarama.Broker) ([]ApiVersion, error) {
+	// Open在后台完成连接和认证，Connected等待其结束
+	if err := broker.Open(cluster.Config()); err != nil && !errors.Is(err, sarama.ErrAlreadyConnected) {
+		return nil, err
+	}
+	if _, err := broker.Connected(); err != nil {
+		return nil, err
+	}
+	response, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if response.ErrorCode != 0 {
+		return nil, sarama.KError(response.ErrorCode)
+	}
+	versions := make([]ApiVersion, 0, len(response.ApiKeys))
+	for _, key := range response.ApiKeys {
+		versions = append(versions, ApiVersion{ApiKey: key.ApiKey, MinVersion: key.MinVersion, MaxVersion: key.MaxVersion})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ApiKey < versions[j].ApiKey })
+	return versions, nil
+}
+
+// advertisedListeners 获取broker的advertised.listeners配置，没有权限或获取失败时返回空
+func advertisedListeners(cluster cluster_tools.Cluster, id int32) string {
+	entries, err := cluster.Admin().DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.BrokerResource,
+		Name:        fmt.Sprintf("%d", id),
+		ConfigNames: []string{"advertised.listeners"},
+	})
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.Name == "advertised.listeners" {
+			return entry.Value
+		}
+	}
+	return ""
+}
+
+// PrintBrokers 以表格输出broker列表，无法连接的broker标红
+func PrintBrokers(brokers []BrokerInfo) {
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, b := range brokers {
+		controller := ""
+		if b.Controller {
+			controller = "*"
+		}
+		rack := b.Rack
+		if rack == "" {
+			rack = "-"
+		}
+		listeners := b.AdvertisedListeners
+		if listeners == "" {
+			listeners = "-"
+		}
+		apis := fmt.Sprintf("%d", len(b.ApiVersions))
+		if b.Error != "" {
+			apis = b.Error
+			rowColors[i] = color.New(color.FgRed)
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", b.ID),
+			b.Address,
+			rack,
+			controller,
+			listeners,
+			fmt.Sprintf("%d", b.Partitions),
+			fmt.Sprintf("%d", b.Leaders),
+			b.apiRange(apiKeyProduce),
+			b.apiRange(apiKeyFetch),
+			b.apiRange(apiKeyMetadata),
+			apis,
+		})
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"ID", "ADDRESS", "RACK", "CONTROLLER", "ADVERTISED-LISTENERS", "PARTITIONS", "LEADERS", "PRODUCE", "FETCH", "METADATA", "APIS"}, rows, rowColors)
+}
+
+// PrintBrokersJSON 把broker列表以JSON写到w，包含每个broker支持的所有API版本范围
+func PrintBrokersJSON(w io.Writer, brokers []BrokerInfo) error {
+	data, err := json.MarshalIndent(brokers, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
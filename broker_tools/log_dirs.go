@@ -0,0 +1,220 @@
+package broker_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"sort"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// ReplicaLogDir 一个分区副本在某个broker日志目录上的大小
+type ReplicaLogDir struct {
+	Broker    int32
+	Path      string
+	Topic     string
+	Partition int32
+	Size      int64
+	// OffsetLag 当前副本为LEO与HW的差值，future副本为与当前副本LEO的差值
+	OffsetLag int64
+	// Future 副本正在日志目录之间迁移，这是迁移目标目录上的future副本
+	Future bool
+}
+
+// LogDirUsage 一个broker日志目录的汇总
+type LogDirUsage struct {
+	Broker   int32
+	Path     string
+	Replicas int
+	Size     int64
+	Error    string
+}
+
+// DescribeLogDirs 获取所有broker的日志目录，topics不为空时只保留这些topic的副本
+func DescribeLogDirs(cluster cluster_tools.Cluster, topics []string) ([]LogDirUsage, []ReplicaLogDir, error) {
+	client := cluster.Client()
+	if err := client.RefreshMetadata(); err != nil {
+		return nil, nil, err
+	}
+	var brokerIDs []int32
+	for _, broker := range client.Brokers() {
+		brokerIDs = append(brokerIDs, broker.ID())
+	}
+	logDirs, err := cluster.Admin().DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		wanted[topic] = true
+	}
+
+	var dirs []LogDirUsage
+	var replicas []ReplicaLogDir
+	for broker, metadata := range logDirs {
+		for _, dir := range metadata {
+			usage := LogDirUsage{Broker: broker, Path: dir.Path}
+			if dir.ErrorCode != sarama.ErrNoError {
+				usage.Error = dir.ErrorCode.Error()
+			}
+			for _, topic := range dir.Topics {
+				for _, p := range topic.Partitions {
+					usage.Replicas++
+					usage.Size += p.Size
+					if len(wanted) > 0 && !wanted[topic.Topic] {
+						continue
+					}
+					replicas = append(replicas, ReplicaLogDir{
+						Broker:    broker,
+						Path:      dir.Path,
+						Topic:     topic.Topic,
+						Partition: p.PartitionID,
+						Size:      p.Size,
+						OffsetLag: p.OffsetLag,
+						Future:    p.IsTemporary,
+					})
+				}
+			}
+			dirs = append(dirs, usage)
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Broker != dirs[j].Broker {
+			return dirs[i].Broker < dirs[j].Broker
+		}
+		return dirs[i].Path < dirs[j].Path
+	})
+	sort.Slice(replicas, func(i, j int) bool {
+		a, b := replicas[i], replicas[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		return a.Broker < b.Broker
+	})
+	return dirs, replicas, nil
+}
+
+// topicUsage 一个topic在所有broker上的副本总大小
+type topicUsage struct {
+	topic    string
+	replicas int
+	size     int64
+}
+
+// ShowLogDirs 打印每个日志目录的总大小、最大的top个topic和offset差距超过lagThreshold的副本，
+// showPartitions为true时打印每个分区副本在各broker上的大小
+func ShowLogDirs(cluster cluster_tools.Cluster, topics []string, top int, lagThreshold int64, showPartitions bool) error {
+	dirs, replicas, err := DescribeLogDirs(cluster, topics)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	var total int64
+	for i, dir := range dirs {
+		errMsg := dir.Error
+		if errMsg != "" {
+			rowColors[i] = color.New(color.FgRed)
+		}
+		total += dir.Size
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", dir.Broker),
+			dir.Path,
+			fmt.Sprintf("%d", dir.Replicas),
+			format_tools.FormatBytes(dir.Size),
+			errMsg,
+		})
+	}
+	fmt.Println("日志目录:")
+	format_tools.PrintPrettyTableWithColors([]string{"BROKER", "LOG-DIR", "REPLICAS", "SIZE", "ERROR"}, rows, rowColors)
+	fmt.Printf("所有日志目录共 %s\n", format_tools.FormatBytes(total))
+
+	usages := make(map[string]*topicUsage)
+	for _, r := range replicas {
+		if r.Future {
+			continue
+		}
+		if usages[r.Topic] == nil {
+			usages[r.Topic] = &topicUsage{topic: r.Topic}
+		}
+		usages[r.Topic].replicas++
+		usages[r.Topic].size += r.Size
+	}
+	var sorted []*topicUsage
+	for _, u := range usages {
+		sorted = append(sorted, u)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].size != sorted[j].size {
+			return sorted[i].size > sorted[j].size
+		}
+		return sorted[i].topic < sorted[j].topic
+	})
+	if top > 0 && len(sorted) > top {
+		sorted = sorted[:top]
+	}
+	rows = nil
+	for i, u := range sorted {
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), u.topic, fmt.Sprintf("%d", u.replicas), format_tools.FormatBytes(u.size)})
+	}
+	fmt.Printf("占用空间最大的 %d 个topic(所有副本合计):\n", len(sorted))
+	format_tools.PrintPrettyTable([]string{"ID", "TOPIC", "REPLICAS", "SIZE"}, rows)
+
+	if showPartitions {
+		rows = nil
+		rowColors = make(map[int]*color.Color)
+		for i, r := range replicas {
+			future := ""
+			if r.Future {
+				future = "yes"
+				rowColors[i] = color.New(color.FgYellow)
+			}
+			rows = append(rows, []string{
+				r.Topic,
+				fmt.Sprintf("%d", r.Partition),
+				fmt.Sprintf("%d", r.Broker),
+				r.Path,
+				format_tools.FormatBytes(r.Size),
+				format_tools.FormatIntWithCommas(r.OffsetLag),
+				future,
+			})
+		}
+		fmt.Println("分区副本:")
+		format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "BROKER", "LOG-DIR", "SIZE", "OFFSET-LAG", "FUTURE"}, rows, rowColors)
+	}
+
+	rows = nil
+	for _, r := range replicas {
+		if !r.Future && r.OffsetLag <= lagThreshold {
+			continue
+		}
+		kind := "current"
+		if r.Future {
+			kind = "future"
+		}
+		rows = append(rows, []string{
+			r.Topic,
+			fmt.Sprintf("%d", r.Partition),
+			fmt.Sprintf("%d", r.Broker),
+			r.Path,
+			kind,
+			format_tools.FormatBytes(r.Size),
+			format_tools.FormatIntWithCommas(r.OffsetLag),
+		})
+	}
+	if len(rows) == 0 {
+		color.Green("✔没有正在迁移的future副本，也没有offset差距超过 %s 的副本", format_tools.FormatIntWithCommas(lagThreshold))
+		return nil
+	}
+	color.Yellow("正在迁移的future副本和offset差距超过 %s 的副本:", format_tools.FormatIntWithCommas(lagThreshold))
+	format_tools.PrintPrettyTable([]string{"TOPIC", "PARTITION", "BROKER", "LOG-DIR", "TYPE", "SIZE", "OFFSET-LAG"}, rows)
+	return nil
+}
@@ -0,0 +1,225 @@
+package cluster_tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// 支持的认证方式
+const (
+	AuthPlaintext   = "PLAINTEXT"
+	AuthScramSHA256 = "SASL/SCRAM-SHA-256"
+	AuthScramSHA512 = "SASL/SCRAM-SHA-512"
+	AuthPlain       = "SASL/PLAIN"
+	AuthOAuthBearer = "SASL/OAUTHBEARER"
+)
+
+// Options 创建集群连接所需的地址、认证和TLS信息
+type Options struct {
+	Brokers  []string
+	AuthType string
+	Username string
+	Password string
+	// PasswordFile和PasswordCmd在未直接提供密码时使用，凭据命令的标准输出即为密码
+	PasswordFile string
+	PasswordCmd  string
+	// OAUTHBEARER的token来源，命令优先于文件
+	OAuthTokenFile string
+	OAuthTokenCmd  string
+	TLS            TLSOptions
+	Client         ClientOptions
+}
+
+// ClientOptions 客户端调优参数
+type ClientOptions struct {
+	// KafkaVersion 集群版本，如"2.8.0"，决定使用的协议版本。为空时按sarama默认的2.1.0协议通信，
+	// 修改配置(2.3)、分区重分配和删除消费组offset(2.4)等功能需要指定集群的实际版本，见RequireVersion
+	KafkaVersion string `json:"kafka_version,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	// TimeoutSec 连接和读写超时时间，默认10秒
+	TimeoutSec int `json:"timeout_sec,omitempty"`
+}
+
+// Timeout 连接和读写超时时间，未配置时为10秒
+func (c ClientOptions) Timeout() time.Duration {
+	if c.TimeoutSec > 0 {
+		return time.Duration(c.TimeoutSec) * time.Second
+	}
+	return 10 * time.Second
+}
+
+// Cluster 所有命令共用的Kafka集群连接，PLAINTEXT和SASL/SCRAM集群走同一套实现
+type Cluster interface {
+	// Brokers 返回bootstrap broker地址列表
+	Brokers() []string
+	// AuthType 返回当前连接使用的认证方式
+	AuthType() string
+	// TLSEnabled 是否通过SSL/SASL_SSL监听连接
+	TLSEnabled() bool
+	Config() *sarama.Config
+	Client() sarama.Client
+	Admin() sarama.ClusterAdmin
+	// Close 关闭admin和底层client
+	Close() error
+}
+
+type saramaCluster struct {
+	opts   Options
+	config *sarama.Config
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+}
+
+// NewConfig 根据认证信息生成sarama配置
+func NewConfig(opts Options) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	timeout := opts.Client.Timeout()
+	config.Net.DialTimeout = timeout
+	config.Net.ReadTimeout = timeout
+	config.Net.WriteTimeout = timeout
+
+	config.ClientID = "kafka_dog"
+	if opts.Client.ClientID != "" {
+		config.ClientID = opts.Client.ClientID
+	}
+	if opts.Client.KafkaVersion != "" {
+		version, err := sarama.ParseKafkaVersion(opts.Client.KafkaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("无效的Kafka版本 %s: %v", opts.Client.KafkaVersion, err)
+		}
+		config.Version = version
+	}
+
+	// TLS可以单独使用，也可以和SASL认证组合(SASL_SSL)
+	if opts.TLS.Active() {
+		tlsConfig, err := NewTLSConfig(opts.TLS)
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	switch opts.AuthType {
+	case "", AuthPlaintext:
+	case AuthScramSHA256, AuthScramSHA512:
+		if opts.Username == "" || opts.Password == "" {
+			return nil, fmt.Errorf("启用%s认证时，必须提供用户名和密码", opts.AuthType)
+		}
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.User = opts.Username
+		config.Net.SASL.Password = opts.Password
+		if opts.AuthType == AuthScramSHA256 {
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA256} }
+		} else {
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA512} }
+		}
+	case AuthPlain:
+		if opts.Username == "" || opts.Password == "" {
+			return nil, fmt.Errorf("启用%s认证时，必须提供用户名和密码", opts.AuthType)
+		}
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		config.Net.SASL.User = opts.Username
+		config.Net.SASL.Password = opts.Password
+	case AuthOAuthBearer:
+		if opts.OAuthTokenFile == "" && opts.OAuthTokenCmd == "" {
+			return nil, fmt.Errorf("启用%s认证时，必须提供token文件或获取token的命令", opts.AuthType)
+		}
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		config.Net.SASL.TokenProvider = &oauthTokenProvider{tokenFile: opts.OAuthTokenFile, tokenCmd: opts.OAuthTokenCmd}
+	default:
+		return nil, fmt.Errorf("不支持的认证类型: %s", opts.AuthType)
+	}
+	return config, nil
+}
+
+// NewCluster 创建client和admin，调用方负责Close
+func NewCluster(opts Options) (Cluster, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, fmt.Errorf("未指定Kafka地址")
+	}
+	config, err := NewConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	RegisterSecret(opts.Password)
+
+	client, err := sarama.NewClient(opts.Brokers, config)
+	if err != nil {
+		return nil, MaskError(err)
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, MaskError(err)
+	}
+
+	return &saramaCluster{opts: opts, config: config, client: client, admin: admin}, nil
+}
+
+func (c *saramaCluster) Brokers() []string {
+	return c.opts.Brokers
+}
+
+func (c *saramaCluster) AuthType() string {
+	if c.opts.AuthType == "" {
+		return AuthPlaintext
+	}
+	return c.opts.AuthType
+}
+
+func (c *saramaCluster) TLSEnabled() bool {
+	return c.opts.TLS.Active()
+}
+
+func (c *saramaCluster) Config() *sarama.Config {
+	return c.config
+}
+
+func (c *saramaCluster) Client() sarama.Client {
+	return c.client
+}
+
+func (c *saramaCluster) Admin() sarama.ClusterAdmin {
+	return c.admin
+}
+
+func (c *saramaCluster) Close() error {
+	// admin关闭时会同时关闭底层client
+	return c.admin.Close()
+}
+
+// RequireVersion 检查当前使用的协议版本是否支持feature，低于min时sarama会直接拒绝发送请求，
+// 这里提前返回带-kafka-version提示的错误
+func RequireVersion(cluster Cluster, min sarama.KafkaVersion, feature string) error {
+	if current := cluster.Config().Version; !current.IsAtLeast(min) {
+		return fmt.Errorf("%s需要Kafka %s及以上，当前按%s协议通信，请通过-kafka-version指定集群的实际版本", feature, min, current)
+	}
+	return nil
+}
+
+// ParseBrokers 解析逗号分隔的bootstrap地址列表，如"10.0.0.1:9092,[::1]:9092"
+func ParseBrokers(hosts string) []string {
+	var brokers []string
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			brokers = append(brokers, host)
+		}
+	}
+	return brokers
+}
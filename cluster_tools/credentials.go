@@ -0,0 +1,99 @@
+package cluster_tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// 命令行和配置文件都没有提供用户名密码时读取的环境变量
+const (
+	EnvUsername = "KAFKA_DOG_USERNAME"
+	EnvPassword = "KAFKA_DOG_PASSWORD"
+)
+
+var (
+	secretsMu sync.Mutex
+	secrets   []string
+)
+
+// RegisterSecret 登记需要在输出中隐藏的密码或token
+func RegisterSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// MaskSecrets 把文本中已登记的密码和token替换为******，只替换前后不是字母、数字或-_.的完整片段，
+// 因此"kafka"这样的短密码也不会把"kafka_dog"等无关文本替换掉
+func MaskSecrets(text string) string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if !strings.Contains(text, s) {
+			continue
+		}
+		re := regexp.MustCompile(`(^|[^A-Za-z0-9_.\-])` + regexp.QuoteMeta(s) + `($|[^A-Za-z0-9_.\-])`)
+		text = re.ReplaceAllString(text, "${1}******${2}")
+	}
+	return text
+}
+
+// MaskError 返回隐藏了密码和token的错误
+func MaskError(err error) error {
+	if err == nil {
+		return nil
+	}
+	masked := MaskSecrets(err.Error())
+	if masked == err.Error() {
+		return err
+	}
+	return errors.New(masked)
+}
+
+// PrintError 隐藏密码和token后用红色打印错误信息，所有面向用户的错误输出都应通过它打印
+func PrintError(format string, a ...interface{}) {
+	color.Red("%s", MaskSecrets(fmt.Sprintf(format, a...)))
+}
+
+// ResolveCredentials 补全未提供的用户名和密码，密码来源依次为密码文件、凭据命令和环境变量
+func ResolveCredentials(opts Options) (Options, error) {
+	if opts.Username == "" {
+		opts.Username = os.Getenv(EnvUsername)
+	}
+
+	if opts.Password == "" {
+		switch {
+		case opts.PasswordFile != "":
+			data, err := os.ReadFile(opts.PasswordFile)
+			if err != nil {
+				return opts, fmt.Errorf("读取密码文件失败: %v", err)
+			}
+			opts.Password = strings.TrimSpace(string(data))
+		case opts.PasswordCmd != "":
+			password, err := runCommand(opts.PasswordCmd)
+			if err != nil {
+				return opts, MaskError(fmt.Errorf("执行凭据命令失败: %v", err))
+			}
+			opts.Password = password
+		default:
+			opts.Password = os.Getenv(EnvPassword)
+		}
+	}
+
+	RegisterSecret(opts.Password)
+	return opts, nil
+}
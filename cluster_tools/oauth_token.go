@@ -0,0 +1,63 @@
+package cluster_tools
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// oauthTokenProvider 实现sarama.AccessTokenProvider，每次认证时重新从文件或外部命令读取token，
+// 这样token过期后由外部工具刷新即可
+type oauthTokenProvider struct {
+	tokenFile string
+	tokenCmd  string
+}
+
+func (p *oauthTokenProvider) Token() (*sarama.AccessToken, error) {
+	var (
+		token string
+		err   error
+	)
+	if p.tokenCmd != "" {
+		token, err = runCommand(p.tokenCmd)
+		if err != nil {
+			return nil, MaskError(fmt.Errorf("执行获取token的命令失败: %v", err))
+		}
+	} else {
+		data, err := os.ReadFile(p.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取token文件失败: %v", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" {
+		return nil, fmt.Errorf("获取到的OAUTHBEARER token为空")
+	}
+	RegisterSecret(token)
+	return &sarama.AccessToken{Token: token}, nil
+}
+
+// runCommand 通过系统shell执行命令，返回去掉首尾空白的标准输出
+func runCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
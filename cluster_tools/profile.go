@@ -0,0 +1,145 @@
+package cluster_tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ProfileConfig 配置文件结构，类似kubectl的contexts，CurrentContext为默认使用的集群
+type ProfileConfig struct {
+	CurrentContext string             `json:"current_context"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+// Profile 一个命名集群的连接信息
+type Profile struct {
+	Brokers        []string      `json:"brokers"`
+	AuthType       string        `json:"auth_type,omitempty"`
+	Username       string        `json:"username,omitempty"`
+	Password       string        `json:"password,omitempty"`
+	PasswordFile   string        `json:"password_file,omitempty"`
+	PasswordCmd    string        `json:"password_cmd,omitempty"`
+	OAuthTokenFile string        `json:"oauth_token_file,omitempty"`
+	OAuthTokenCmd  string        `json:"oauth_token_cmd,omitempty"`
+	TLS            TLSOptions    `json:"tls"`
+	Client         ClientOptions `json:"client"`
+}
+
+// DefaultProfilePath 配置文件默认路径，可通过环境变量KAFKA_DOG_CONFIG覆盖
+func DefaultProfilePath() string {
+	if path := os.Getenv("KAFKA_DOG_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kafka_dog", "config.json")
+	}
+	return filepath.Join(home, ".kafka_dog", "config.json")
+}
+
+// LoadProfiles 读取配置文件，文件不存在时返回空配置
+func LoadProfiles(path string) (*ProfileConfig, error) {
+	cfg := &ProfileConfig{Profiles: map[string]Profile{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// Save 写回配置文件，文件中可能包含密码，权限设为0600
+func (c *ProfileConfig) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Names 返回排序后的profile名称
+func (c *ProfileConfig) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 按名称获取profile
+func (c *ProfileConfig) Get(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("未找到名为 %s 的集群配置", name)
+	}
+	return p, nil
+}
+
+// ProfileFromOptions 把当前连接信息转换为profile以便保存
+func ProfileFromOptions(opts Options) Profile {
+	return Profile{
+		Brokers:        opts.Brokers,
+		AuthType:       opts.AuthType,
+		Username:       opts.Username,
+		Password:       opts.Password,
+		PasswordFile:   opts.PasswordFile,
+		PasswordCmd:    opts.PasswordCmd,
+		OAuthTokenFile: opts.OAuthTokenFile,
+		OAuthTokenCmd:  opts.OAuthTokenCmd,
+		TLS:            opts.TLS,
+		Client:         opts.Client,
+	}
+}
+
+// MergeProfile 用profile补全命令行未指定的连接信息，命令行参数优先
+func MergeProfile(opts Options, p Profile) Options {
+	if len(opts.Brokers) == 0 {
+		opts.Brokers = p.Brokers
+	}
+	if opts.AuthType == "" || opts.AuthType == AuthPlaintext {
+		opts.AuthType = p.AuthType
+	}
+	if opts.Username == "" {
+		opts.Username = p.Username
+	}
+	if opts.Password == "" && opts.PasswordFile == "" && opts.PasswordCmd == "" {
+		opts.Password = p.Password
+		opts.PasswordFile = p.PasswordFile
+		opts.PasswordCmd = p.PasswordCmd
+	}
+	if opts.OAuthTokenFile == "" && opts.OAuthTokenCmd == "" {
+		opts.OAuthTokenFile = p.OAuthTokenFile
+		opts.OAuthTokenCmd = p.OAuthTokenCmd
+	}
+	if !opts.TLS.Active() {
+		opts.TLS = p.TLS
+	}
+	if opts.Client.KafkaVersion == "" {
+		opts.Client.KafkaVersion = p.Client.KafkaVersion
+	}
+	if opts.Client.ClientID == "" {
+		opts.Client.ClientID = p.Client.ClientID
+	}
+	if opts.Client.TimeoutSec == 0 {
+		opts.Client.TimeoutSec = p.Client.TimeoutSec
+	}
+	if opts.AuthType == "" {
+		opts.AuthType = AuthPlaintext
+	}
+	return opts
+}
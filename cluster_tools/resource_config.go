@@ -0,0 +1,144 @@
+package cluster_tools
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// ConfigSourceName 配置值的来源：默认值、静态配置(server.properties)或动态覆盖
+func ConfigSourceName(source sarama.ConfigSource) string {
+	switch source {
+	case sarama.SourceTopic:
+		return "dynamic topic override"
+	case sarama.SourceDynamicBroker:
+		return "dynamic broker override"
+	case sarama.SourceDynamicDefaultBroker:
+		return "dynamic cluster default"
+	case sarama.SourceStaticBroker:
+		return "static broker config"
+	case sarama.SourceDefault:
+		return "default"
+	}
+	return "unknown"
+}
+
+// ConfigValue 敏感配置不显示明文
+func ConfigValue(entry sarama.ConfigEntry) string {
+	if entry.Sensitive {
+		return "******"
+	}
+	return entry.Value
+}
+
+// ConfigChange 修改前后有变化的配置项
+type ConfigChange struct {
+	Name         string
+	Before       string
+	BeforeSource string
+	After        string
+	AfterSource  string
+}
+
+// DiffConfigs 比较修改前后的配置，返回值或来源发生变化的配置项
+func DiffConfigs(before, after []sarama.ConfigEntry) []ConfigChange {
+	beforeMap := make(map[string]sarama.ConfigEntry, len(before))
+	for _, entry := range before {
+		beforeMap[entry.Name] = entry
+	}
+	afterMap := make(map[string]sarama.ConfigEntry, len(after))
+	for _, entry := range after {
+		afterMap[entry.Name] = entry
+	}
+
+	names := make(map[string]struct{})
+	for name := range beforeMap {
+		names[name] = struct{}{}
+	}
+	for name := range afterMap {
+		names[name] = struct{}{}
+	}
+
+	var changes []ConfigChange
+	for name := range names {
+		b, hasBefore := beforeMap[name]
+		a, hasAfter := afterMap[name]
+		if hasBefore && hasAfter && b.Value == a.Value && b.Source == a.Source {
+			continue
+		}
+		change := ConfigChange{Name: name}
+		if hasBefore {
+			change.Before = ConfigValue(b)
+			change.BeforeSource = ConfigSourceName(b.Source)
+		}
+		if hasAfter {
+			change.After = ConfigValue(a)
+			change.AfterSource = ConfigSourceName(a.Source)
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// IncrementalConfigEntries 把set、delete、append操作转换为IncrementalAlterConfigs请求的配置项，
+// append用于给列表类型的配置追加值，如leader.replication.throttled.replicas
+func IncrementalConfigEntries(set map[string]string, deleteKeys []string, appendValues map[string]string) map[string]sarama.IncrementalAlterConfigsEntry {
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry)
+	for key, value := range set {
+		v := value
+		entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &v}
+	}
+	for key, value := range appendValues {
+		v := value
+		entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationAppend, Value: &v}
+	}
+	for _, key := range deleteKeys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+	}
+	return entries
+}
+
+// PlannedConfigChanges 根据当前配置推算修改后的值，用于validate-only模式和修改前的预览，
+// source为set和append后配置的来源
+func PlannedConfigChanges(before []sarama.ConfigEntry, set map[string]string, deleteKeys []string,
+	appendValues map[string]string, source sarama.ConfigSource) []ConfigChange {
+	current := make(map[string]sarama.ConfigEntry, len(before))
+	for _, entry := range before {
+		current[entry.Name] = entry
+	}
+	change := func(name, after, afterSource string) ConfigChange {
+		c := ConfigChange{Name: name, After: after, AfterSource: afterSource}
+		if entry, ok := current[name]; ok {
+			c.Before = ConfigValue(entry)
+			c.BeforeSource = ConfigSourceName(entry.Source)
+		}
+		return c
+	}
+
+	var changes []ConfigChange
+	for name, value := range set {
+		changes = append(changes, change(name, value, ConfigSourceName(source)))
+	}
+	for name, value := range appendValues {
+		after := value
+		if entry, ok := current[name]; ok && entry.Value != "" {
+			after = entry.Value + "," + value
+		}
+		changes = append(changes, change(name, after, ConfigSourceName(source)))
+	}
+	for _, name := range deleteKeys {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		changes = append(changes, change(name, "(恢复为上级配置)", ""))
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -7,12 +7,14 @@ import (
 	"syscall"
 	"time"
 
+	"kafka_dog/cluster_tools"
+
 	"github.com/IBM/sarama"
 )
 
 // 从头消费X条消息
-func ConsumeFromBeginning(brokers []string, config *sarama.Config, topic string, count int) error {
-	consumer, err := sarama.NewConsumer(brokers, config)
+func ConsumeFromBeginning(cluster cluster_tools.Cluster, topic string, count int) error {
+	consumer, err := sarama.NewConsumerFromClient(cluster.Client())
 	if err != nil {
 		return fmt.Errorf("创建consumer失败: %v", err)
 	}
@@ -52,8 +54,8 @@ func ConsumeFromBeginning(brokers []string, config *sarama.Config, topic string,
 }
 
 // 从最新持续消费消息，按Ctrl+C优雅退出
-func ConsumeFromLastest(brokers []string, config *sarama.Config, topic string) error {
-	consumer, err := sarama.NewConsumer(brokers, config)
+func ConsumeFromLastest(cluster cluster_tools.Cluster, topic string) error {
+	consumer, err := sarama.NewConsumerFromClient(cluster.Client())
 	if err != nil {
 		return fmt.Errorf("创建consumer失败: %v", err)
 	}
@@ -96,3 +98,56 @@ func ConsumeFromLastest(brokers []string, config *sarama.Config, topic string) e
 	fmt.Println("收到退出信号，优雅退出。")
 	return nil
 }
+
+// SampleKeys 从每个分区最新的perPartition条消息中采样key，返回每个分区采样到的key(包括nil key)
+func SampleKeys(cluster cluster_tools.Cluster, topic string, perPartition int) (map[int32][][]byte, error) {
+	client := cluster.Client()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("创建consumer失败: %v", err)
+	}
+	defer consumer.Close()
+
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("获取分区失败: %v", err)
+	}
+
+	keys := make(map[int32][][]byte, len(partitions))
+	for _, partition := range partitions {
+		oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return nil, fmt.Errorf("获取分区 %d 的offset失败: %v", partition, err)
+		}
+		newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("获取分区 %d 的offset失败: %v", partition, err)
+		}
+		if newest <= oldest {
+			continue
+		}
+		start := newest - int64(perPartition)
+		if start < oldest {
+			start = oldest
+		}
+
+		pc, err := consumer.ConsumePartition(topic, partition, start)
+		if err != nil {
+			return nil, fmt.Errorf("消费分区 %d 失败: %v", partition, err)
+		}
+	read:
+		for {
+			select {
+			case msg := <-pc.Messages():
+				keys[partition] = append(keys[partition], msg.Key)
+				if msg.Offset >= newest-1 {
+					break read
+				}
+			case <-time.After(2 * time.Second):
+				break read
+			}
+		}
+		pc.Close()
+	}
+	return keys, nil
+}
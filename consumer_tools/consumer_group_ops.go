@@ -2,24 +2,17 @@ package consumer_tools
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"kafka_dog/cluster_tools"
+
 	"github.com/IBM/sarama"
 )
 
 // 获取所有消费组
-func GetAllConsumerGroups(brokers []string, config *sarama.Config, keyword string) ([]string, error) {
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		return nil, err
-	}
-	defer client.Close()
-
-	admin, err := sarama.NewClusterAdminFromClient(client)
-	if err != nil {
-		return nil, err
-	}
-	defer admin.Close()
+func GetAllConsumerGroups(cluster cluster_tools.Cluster, keyword string) ([]string, error) {
+	admin := cluster.Admin()
 
 	groups, err := admin.ListConsumerGroups()
 	if err != nil {
@@ -37,18 +30,57 @@ func GetAllConsumerGroups(brokers []string, config *sarama.Config, keyword strin
 	return groupNames, nil
 }
 
-func GetConsumerGroupDetailsTable(brokers []string, config *sarama.Config, group, groupTopicKeyword string) ([][]string, error) {
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		return nil, err
+// groupMember 分区分配到的消费组成员
+type groupMember struct {
+	memberID string
+	host     string
+	clientID string
+}
+
+// groupAssignments 解析消费组成员的分配，返回topic -> partition -> 成员
+func groupAssignments(desc *sarama.GroupDescription) map[string]map[int32]groupMember {
+	assignments := make(map[string]map[int32]groupMember)
+	for _, member := range desc.Members {
+		assignment, err := member.GetMemberAssignment()
+		if err != nil || assignment == nil {
+			continue
+		}
+		for topic, partitions := range assignment.Topics {
+			if assignments[topic] == nil {
+				assignments[topic] = make(map[int32]groupMember)
+			}
+			for _, p := range partitions {
+				assignments[topic][p] = groupMember{memberID: member.MemberId, host: member.ClientHost, clientID: member.ClientId}
+			}
+		}
 	}
-	defer client.Close()
+	return assignments
+}
 
-	admin, err := sarama.NewClusterAdminFromClient(client)
-	if err != nil {
-		return nil, err
+// PartitionLag 消费组在一个分区上的已提交offset、最新offset和分配到的成员
+type PartitionLag struct {
+	Topic     string
+	Partition int32
+	// Committed 为-1表示还没有提交过offset
+	Committed int64
+	// LogEnd 为-1表示获取最新offset失败
+	LogEnd int64
+	member groupMember
+}
+
+// Lag 未消费的消息数，offset未知时返回-1
+func (p PartitionLag) Lag() int64 {
+	if p.Committed < 0 || p.LogEnd < 0 {
+		return -1
 	}
-	defer admin.Close()
+	return p.LogEnd - p.Committed
+}
+
+// GetConsumerGroupLag 获取消费组每个分区的offset和lag，按topic和分区排序。
+// 已分配但还没有提交过offset的分区也会返回；没有活跃成员的消费组成员为"-"
+func GetConsumerGroupLag(cluster cluster_tools.Cluster, group, groupTopicKeyword string) ([]PartitionLag, error) {
+	client := cluster.Client()
+	admin := cluster.Admin()
 
 	// 获取消费组描述
 	desc, err := admin.DescribeConsumerGroups([]string{group})
@@ -58,73 +90,105 @@ func GetConsumerGroupDetailsTable(brokers []string, config *sarama.Config, group
 	if len(desc) == 0 {
 		return nil, fmt.Errorf("未找到消费组: %s", group)
 	}
-	// fmt.Println("消费组描述:", desc[0])
-	// 获取消费组分配的topic/partition
+	if desc[0].Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("获取消费组 %s 失败: %v", group, desc[0].Err)
+	}
+	assignments := groupAssignments(desc[0])
+
+	// 获取消费组已提交的offset
 	offsets, err := admin.ListConsumerGroupOffsets(group, nil)
 	if err != nil {
 		return nil, err
 	}
-	// fmt.Println("消费组分配的topic/partition:", offsets.Blocks)
-	var table [][]string
 
-	for topic, partitions := range offsets.Blocks {
-		for partition, block := range partitions {
-			// 获取log-end-offset
-			endOffset, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
-			if err != nil {
-				endOffset = -1
-			}
-			lag := int64(-1)
-			if block.Offset >= 0 && endOffset >= 0 {
-				lag = endOffset - block.Offset
+	// 已提交offset和已分配的分区的并集，filtered记录被topic关键字过滤掉的分区数
+	partitions := make(map[string]map[int32]bool)
+	filtered := 0
+	add := func(topic string, partition int32) {
+		if groupTopicKeyword != "" && !strings.Contains(topic, groupTopicKeyword) {
+			filtered++
+			return
+		}
+		if partitions[topic] == nil {
+			partitions[topic] = make(map[int32]bool)
+		}
+		partitions[topic][partition] = true
+	}
+	for topic, blocks := range offsets.Blocks {
+		for partition, block := range blocks {
+			if block.Offset >= 0 {
+				add(topic, partition)
 			}
+		}
+	}
+	for topic, members := range assignments {
+		for partition := range members {
+			add(topic, partition)
+		}
+	}
+	if len(partitions) == 0 && filtered > 0 {
+		return nil, fmt.Errorf("消费组 %s 没有topic名称包含关键字 %q 的分区", group, groupTopicKeyword)
+	}
+	if len(partitions) == 0 && len(desc[0].Members) == 0 {
+		return nil, fmt.Errorf("消费组 %s 没有活跃成员，也没有已提交的offset(状态: %s)", group, desc[0].State)
+	}
 
-			consumerID := ""
-			host := ""
-			clientID := ""
-			// 查找member信息
-			for _, groupDesc := range desc {
-				for _, member := range groupDesc.Members {
-					assignment, err := member.GetMemberAssignment()
-					if err != nil {
-						continue
-					}
-					if assignment == nil {
-						continue
-					}
-					for t, ps := range assignment.Topics {
-						if t == topic {
-							for _, p := range ps {
-								if int32(p) == partition {
-									consumerID = member.ClientId
-									host = member.ClientHost
-									clientID = member.MemberId
-								}
-							}
-						}
-					}
-				}
+	var lags []PartitionLag
+	for topic, ps := range partitions {
+		for partition := range ps {
+			p := PartitionLag{Topic: topic, Partition: partition, Committed: -1}
+			if block := offsets.GetBlock(topic, partition); block != nil {
+				p.Committed = block.Offset
 			}
-
-			row := []string{
-				group,
-				topic,
-				fmt.Sprintf("%d", partition),
-				fmt.Sprintf("%d", block.Offset),
-				fmt.Sprintf("%d", endOffset),
-				fmt.Sprintf("%d", lag),
-				clientID,
-				host,
-				consumerID,
+			// 获取log-end-offset
+			if p.LogEnd, err = client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+				p.LogEnd = -1
 			}
-
-			if groupTopicKeyword != "" && !strings.Contains(topic, groupTopicKeyword) {
-				continue
-			} else {
-				table = append(table, row)
+			member, ok := assignments[topic][partition]
+			if !ok {
+				member = groupMember{memberID: "-", host: "-", clientID: "-"}
 			}
-			// table = append(table, row)
+			p.member = member
+			lags = append(lags, p)
+		}
+	}
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
 		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	return lags, nil
+}
+
+// formatOffset offset未知时显示"-"
+func formatOffset(offset int64) string {
+	if offset < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", offset)
+}
+
+// GetConsumerGroupDetailsTable 返回消费组每个分区的已提交offset、最新offset、lag和分配到的成员，
+// offset和lag未知时显示为"-"
+func GetConsumerGroupDetailsTable(cluster cluster_tools.Cluster, group, groupTopicKeyword string) ([][]string, error) {
+	lags, err := GetConsumerGroupLag(cluster, group, groupTopicKeyword)
+	if err != nil {
+		return nil, err
+	}
+	var table [][]string
+	for _, p := range lags {
+		table = append(table, []string{
+			group,
+			p.Topic,
+			fmt.Sprintf("%d", p.Partition),
+			formatOffset(p.Committed),
+			formatOffset(p.LogEnd),
+			formatOffset(p.Lag()),
+			p.member.memberID,
+			p.member.host,
+			p.member.clientID,
+		})
 	}
 	return table, nil
 }
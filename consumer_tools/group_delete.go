@@ -0,0 +1,156 @@
+package consumer_tools
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// MatchGroups 按精确名称、关键词或正则匹配消费组，三者只取优先级最高的一个：名称 > 正则 > 关键词
+func MatchGroups(cluster cluster_tools.Cluster, name, keyword, pattern string) ([]string, error) {
+	if name == "" && keyword == "" && pattern == "" {
+		return nil, fmt.Errorf("必须指定消费组名称、关键词或正则表达式")
+	}
+
+	groups, err := cluster.Admin().ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var re *regexp.Regexp
+	if name == "" && pattern != "" {
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式无效: %v", err)
+		}
+	}
+
+	var matched []string
+	for group := range groups {
+		switch {
+		case name != "":
+			if group == name {
+				matched = append(matched, group)
+			}
+		case re != nil:
+			if re.MatchString(group) {
+				matched = append(matched, group)
+			}
+		case strings.Contains(group, keyword):
+			matched = append(matched, group)
+		}
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// PrintGroupsPreview 打印即将被操作的消费组及其状态和成员数
+func PrintGroupsPreview(cluster cluster_tools.Cluster, groups []string) {
+	states := make(map[string]*sarama.GroupDescription)
+	if desc, err := cluster.Admin().DescribeConsumerGroups(groups); err == nil {
+		for _, d := range desc {
+			states[d.GroupId] = d
+		}
+	}
+
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, group := range groups {
+		state, members := "?", "?"
+		if d := states[group]; d != nil {
+			state = d.State
+			members = fmt.Sprintf("%d", len(d.Members))
+			// 有成员的消费组会被broker拒绝删除
+			if len(d.Members) > 0 {
+				rowColors[i] = color.New(color.FgYellow)
+			}
+		}
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), group, state, members})
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"ID", "GROUP", "STATE", "MEMBERS"}, rows, rowColors)
+}
+
+// DeleteGroups 逐个删除消费组，返回删除失败的数量
+func DeleteGroups(cluster cluster_tools.Cluster, groups []string) int {
+	failed := 0
+	for _, group := range groups {
+		if err := cluster.Admin().DeleteConsumerGroup(group); err != nil {
+			if errors.Is(err, sarama.ErrNonEmptyGroup) {
+				color.Red("删除消费组 %s 失败: 消费组还有活跃成员", group)
+			} else {
+				cluster_tools.PrintError("删除消费组 %s 失败: %v", group, err)
+			}
+			failed++
+			continue
+		}
+		color.Green("✔已删除消费组 %s", group)
+	}
+	return failed
+}
+
+// GroupTopicOffsets 消费组在topic上已提交offset的分区
+func GroupTopicOffsets(cluster cluster_tools.Cluster, group, topic string) ([]int32, error) {
+	partitions, err := cluster.Client().Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("获取topic %s 的分区失败: %v", topic, err)
+	}
+	offsets, err := cluster.Admin().ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, err
+	}
+	var committed []int32
+	for _, p := range partitions {
+		if block := offsets.GetBlock(topic, p); block != nil && block.Offset >= 0 {
+			committed = append(committed, p)
+		}
+	}
+	sort.Slice(committed, func(i, j int) bool { return committed[i] < committed[j] })
+	return committed, nil
+}
+
+// DeleteGroupTopicOffsets 通过OffsetDelete API删除消费组在topic上所有分区已提交的offset，返回删除失败的分区数。
+// 消费组中还有订阅该topic的活跃成员时broker会拒绝删除
+func DeleteGroupTopicOffsets(cluster cluster_tools.Cluster, group, topic string, partitions []int32) (int, error) {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "删除消费组offset"); err != nil {
+		return 0, err
+	}
+	request := &sarama.DeleteOffsetsRequest{Group: group}
+	for _, p := range partitions {
+		request.AddPartition(topic, p)
+	}
+
+	coordinator, err := cluster.Admin().Coordinator(group)
+	if err != nil {
+		return 0, err
+	}
+	response, err := coordinator.DeleteOffsets(request)
+	if err != nil {
+		return 0, err
+	}
+	if !errors.Is(response.ErrorCode, sarama.ErrNoError) {
+		return 0, response.ErrorCode
+	}
+
+	failed := 0
+	for _, p := range partitions {
+		kerr, ok := response.Errors[topic][p]
+		if !ok || errors.Is(kerr, sarama.ErrNoError) {
+			continue
+		}
+		if errors.Is(kerr, sarama.ErrGroupSubscribedToTopic) {
+			color.Red("删除 %s-%d 的offset失败: 消费组还有订阅该topic的活跃成员", topic, p)
+		} else {
+			cluster_tools.PrintError("删除 %s-%d 的offset失败: %v", topic, p, kerr)
+		}
+		failed++
+	}
+	return failed, nil
+}
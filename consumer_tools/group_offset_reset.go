@@ -0,0 +1,365 @@
+package consumer_tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// 重置offset的策略
+const (
+	ResetToEarliest = "earliest"
+	ResetToLatest   = "latest"
+	ResetToDatetime = "datetime"
+	ResetShiftBy    = "shift"
+	ResetToOffset   = "offset"
+	ResetFromFile   = "file"
+)
+
+// OffsetReset 一个分区重置前后的offset
+type OffsetReset struct {
+	Topic     string
+	Partition int32
+	// Current 当前已提交的offset，-1表示没有提交过
+	Current  int64
+	LogStart int64
+	LogEnd   int64
+	New      int64
+	// Clamped 目标offset超出了日志范围，已调整到最早或最新的offset
+	Clamped bool
+}
+
+// ParsePartitions 解析"0,1,2"格式的分区列表
+func ParsePartitions(s string) ([]int32, error) {
+	var partitions []int32
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		p, err := strconv.ParseInt(part, 10, 32)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("分区 %q 无效", part)
+		}
+		partitions = append(partitions, int32(p))
+	}
+	return partitions, nil
+}
+
+// parseDatetime 解析时间，支持RFC3339、本地时间"2006-01-02 15:04:05"、"2006-01-02"和毫秒时间戳
+func parseDatetime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("时间 %q 格式无效，支持2006-01-02T15:04:05+08:00、2006-01-02 15:04:05、2006-01-02或毫秒时间戳", value)
+}
+
+// readOffsetFile 读取"topic,partition,offset"格式的文件，每行一个分区，#开头的行为注释
+func readOffsetFile(path string) (map[string]map[int32]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	offsets := make(map[string]map[int32]int64)
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s 第%d行格式无效，应为topic,partition,offset", path, lineNo)
+		}
+		topic := strings.TrimSpace(fields[0])
+		partition, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s 第%d行分区无效: %v", path, lineNo, err)
+		}
+		offset, err := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s 第%d行offset无效: %v", path, lineNo, err)
+		}
+		if offsets[topic] == nil {
+			offsets[topic] = make(map[int32]int64)
+		}
+		offsets[topic][int32(partition)] = offset
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("%s 中没有offset", path)
+	}
+	return offsets, nil
+}
+
+// CheckGroupInactive 消费组有活跃成员时返回错误，重置offset会被正在消费的成员覆盖
+func CheckGroupInactive(cluster cluster_tools.Cluster, group string) error {
+	desc, err := cluster.Admin().DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return err
+	}
+	for _, d := range desc {
+		if len(d.Members) > 0 {
+			return fmt.Errorf("消费组 %s 有 %d 个活跃成员(状态%s)，请先停止所有消费者再操作", group, len(d.Members), d.State)
+		}
+	}
+	return nil
+}
+
+// groupScope 确定要重置的分区：指定topic时为该topic的指定分区或全部分区，否则为消费组已提交过offset的所有分区
+func groupScope(cluster cluster_tools.Cluster, group, topic string, partitions []int32) (map[string][]int32, error) {
+	scope := make(map[string][]int32)
+	if topic != "" {
+		if len(partitions) > 0 {
+			all, err := cluster.Client().Partitions(topic)
+			if err != nil {
+				return nil, fmt.Errorf("获取topic %s 的分区失败: %v", topic, err)
+			}
+			exists := make(map[int32]bool, len(all))
+			for _, p := range all {
+				exists[p] = true
+			}
+			for _, p := range partitions {
+				if !exists[p] {
+					return nil, fmt.Errorf("topic %s 没有分区 %d", topic, p)
+				}
+			}
+			scope[topic] = partitions
+			return scope, nil
+		}
+		all, err := cluster.Client().Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("获取topic %s 的分区失败: %v", topic, err)
+		}
+		scope[topic] = all
+		return scope, nil
+	}
+
+	offsets, err := cluster.Admin().ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		return nil, err
+	}
+	for t, blocks := range offsets.Blocks {
+		for p, block := range blocks {
+			if block.Offset >= 0 {
+				scope[t] = append(scope[t], p)
+			}
+		}
+	}
+	if len(scope) == 0 {
+		return nil, fmt.Errorf("消费组 %s 没有已提交的offset，请通过-topic-name指定要重置的topic", group)
+	}
+	return scope, nil
+}
+
+// PlanOffsetReset 按策略计算每个分区的目标offset，目标offset会被限制在[最早offset, 最新offset]范围内
+func PlanOffsetReset(cluster cluster_tools.Cluster, group, topic string, partitions []int32, strategy, value string) ([]OffsetReset, error) {
+	var (
+		scope       map[string][]int32
+		fileOffsets map[string]map[int32]int64
+		datetime    time.Time
+		number      int64
+		err         error
+	)
+
+	switch strategy {
+	case ResetToEarliest, ResetToLatest:
+	case ResetToDatetime:
+		if datetime, err = parseDatetime(value); err != nil {
+			return nil, err
+		}
+	case ResetShiftBy, ResetToOffset:
+		if number, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64); err != nil {
+			return nil, fmt.Errorf("%s策略需要通过-reset-value指定整数: %v", strategy, err)
+		}
+	case ResetFromFile:
+		if fileOffsets, err = readOffsetFile(value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的重置策略 %q，可选: earliest, latest, datetime, shift, offset, file", strategy)
+	}
+
+	if fileOffsets != nil {
+		// 同时指定了topic或分区时只重置文件中与之匹配的分区
+		wanted := make(map[int32]bool, len(partitions))
+		for _, p := range partitions {
+			wanted[p] = true
+		}
+		scope = make(map[string][]int32)
+		for t, ps := range fileOffsets {
+			if topic != "" && t != topic {
+				continue
+			}
+			for p := range ps {
+				if len(wanted) > 0 && !wanted[p] {
+					continue
+				}
+				scope[t] = append(scope[t], p)
+			}
+		}
+		if len(scope) == 0 {
+			return nil, fmt.Errorf("%s 中没有与指定的topic和分区匹配的offset", value)
+		}
+	} else if scope, err = groupScope(cluster, group, topic, partitions); err != nil {
+		return nil, err
+	}
+
+	committed, err := cluster.Admin().ListConsumerGroupOffsets(group, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	client := cluster.Client()
+	var resets []OffsetReset
+	for t, ps := range scope {
+		for _, p := range ps {
+			r := OffsetReset{Topic: t, Partition: p, Current: -1}
+			if block := committed.GetBlock(t, p); block != nil {
+				r.Current = block.Offset
+			}
+			if r.LogStart, err = client.GetOffset(t, p, sarama.OffsetOldest); err != nil {
+				return nil, fmt.Errorf("获取 %s-%d 的最早offset失败: %v", t, p, err)
+			}
+			if r.LogEnd, err = client.GetOffset(t, p, sarama.OffsetNewest); err != nil {
+				return nil, fmt.Errorf("获取 %s-%d 的最新offset失败: %v", t, p, err)
+			}
+
+			switch strategy {
+			case ResetToEarliest:
+				r.New = r.LogStart
+			case ResetToLatest:
+				r.New = r.LogEnd
+			case ResetToDatetime:
+				// 返回第一条时间戳不早于datetime的消息的offset，没有这样的消息时为-1
+				offset, err := client.GetOffset(t, p, datetime.UnixMilli())
+				if err != nil {
+					return nil, fmt.Errorf("按时间查询 %s-%d 的offset失败: %v", t, p, err)
+				}
+				r.New = offset
+				if offset < 0 {
+					r.New = r.LogEnd
+				}
+			case ResetShiftBy:
+				if r.Current < 0 {
+					return nil, fmt.Errorf("%s-%d 没有已提交的offset，无法使用shift策略", t, p)
+				}
+				r.New = r.Current + number
+			case ResetToOffset:
+				r.New = number
+			case ResetFromFile:
+				r.New = fileOffsets[t][p]
+			}
+
+			if r.New < r.LogStart {
+				r.New, r.Clamped = r.LogStart, true
+			} else if r.New > r.LogEnd {
+				r.New, r.Clamped = r.LogEnd, true
+			}
+			resets = append(resets, r)
+		}
+	}
+
+	sort.Slice(resets, func(i, j int) bool {
+		if resets[i].Topic != resets[j].Topic {
+			return resets[i].Topic < resets[j].Topic
+		}
+		return resets[i].Partition < resets[j].Partition
+	})
+	return resets, nil
+}
+
+// PrintOffsetResetPreview 打印每个分区当前和重置后的offset以及lag
+func PrintOffsetResetPreview(group string, resets []OffsetReset) {
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, r := range resets {
+		current, lagBefore := "-", "-"
+		if r.Current >= 0 {
+			current = fmt.Sprintf("%d", r.Current)
+			lagBefore = format_tools.FormatIntWithCommas(r.LogEnd - r.Current)
+		}
+		note := ""
+		if r.Clamped {
+			note = "超出日志范围，已调整"
+			rowColors[i] = color.New(color.FgYellow)
+		}
+		rows = append(rows, []string{
+			r.Topic,
+			fmt.Sprintf("%d", r.Partition),
+			current,
+			fmt.Sprintf("%d", r.New),
+			fmt.Sprintf("%d", r.LogStart),
+			fmt.Sprintf("%d", r.LogEnd),
+			lagBefore,
+			format_tools.FormatIntWithCommas(r.LogEnd - r.New),
+			note,
+		})
+	}
+	fmt.Println("消费组:", group)
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "CURRENT", "NEW", "LOG-START", "LOG-END", "LAG-BEFORE", "LAG-AFTER", "NOTE"}, rows, rowColors)
+}
+
+// ResetGroupOffsets 向消费组的coordinator提交新的offset，返回提交失败的分区数
+func ResetGroupOffsets(cluster cluster_tools.Cluster, group string, resets []OffsetReset) (int, error) {
+	// 提交前再检查一次，避免预览期间有消费者加入
+	if err := CheckGroupInactive(cluster, group); err != nil {
+		return 0, err
+	}
+
+	// 不属于任何generation的提交(generation为-1)只能用于没有活跃成员的消费组
+	request := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: -1,
+		RetentionTime:           -1,
+	}
+	for _, r := range resets {
+		request.AddBlock(r.Topic, r.Partition, r.New, 0, "")
+	}
+
+	coordinator, err := cluster.Admin().Coordinator(group)
+	if err != nil {
+		return 0, err
+	}
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for topic, partitions := range response.Errors {
+		for partition, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				cluster_tools.PrintError("提交 %s-%d 的offset失败: %v", topic, partition, kerr)
+				failed++
+			}
+		}
+	}
+	return failed, nil
+}
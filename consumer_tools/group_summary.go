@@ -0,0 +1,149 @@
+package consumer_tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// GroupSummary 消费组的状态、协议、成员数和coordinator
+type GroupSummary struct {
+	Group string
+	// State 为Stable、PreparingRebalance、CompletingRebalance、Empty或Dead
+	State        string
+	ProtocolType string
+	// Protocol consumer类型的消费组为分区分配策略，如range、roundrobin、cooperative-sticky
+	Protocol    string
+	Members     int
+	Coordinator string
+}
+
+// newGroupSummary 从消费组描述生成摘要，coordinator需要单独查询
+func newGroupSummary(desc *sarama.GroupDescription) GroupSummary {
+	return GroupSummary{
+		Group:        desc.GroupId,
+		State:        desc.State,
+		ProtocolType: desc.ProtocolType,
+		Protocol:     desc.Protocol,
+		Members:      len(desc.Members),
+	}
+}
+
+// DescribeGroupSummary 获取消费组的状态、协议、分配策略和coordinator broker
+func DescribeGroupSummary(cluster cluster_tools.Cluster, group string) (GroupSummary, error) {
+	desc, err := cluster.Admin().DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return GroupSummary{}, err
+	}
+	if len(desc) == 0 {
+		return GroupSummary{}, fmt.Errorf("未找到消费组: %s", group)
+	}
+	if desc[0].Err != sarama.ErrNoError {
+		return GroupSummary{}, fmt.Errorf("获取消费组 %s 失败: %v", group, desc[0].Err)
+	}
+	summary := newGroupSummary(desc[0])
+	// coordinator查询失败时在header中显示原因，不影响其他信息
+	if coordinator, err := cluster.Admin().Coordinator(group); err != nil {
+		summary.Coordinator = fmt.Sprintf("获取失败(%v)", cluster_tools.MaskError(err))
+	} else {
+		summary.Coordinator = fmt.Sprintf("%d (%s)", coordinator.ID(), coordinator.Addr())
+	}
+	return summary, nil
+}
+
+// stateColor 正在rebalance的消费组标黄，Dead标红
+func stateColor(state string) *color.Color {
+	switch state {
+	case "PreparingRebalance", "CompletingRebalance":
+		return color.New(color.FgYellow)
+	case "Dead":
+		return color.New(color.FgRed)
+	}
+	return nil
+}
+
+// orDash 空字符串显示为"-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// PrintGroupHeader 打印消费组的状态、协议类型、分配策略、成员数和coordinator
+func PrintGroupHeader(s GroupSummary) {
+	state := s.State
+	if c := stateColor(s.State); c != nil {
+		state = c.Sprint(s.State)
+	}
+	fmt.Printf("消费组: %s    状态: %s    成员数: %d\n", s.Group, state, s.Members)
+	fmt.Printf("协议类型: %s    分配策略: %s    Coordinator: %s\n", orDash(s.ProtocolType), orDash(s.Protocol), orDash(s.Coordinator))
+}
+
+// ParseGroupStates 解析逗号分隔的消费组状态，不区分大小写，返回小写状态集合
+func ParseGroupStates(s string) (map[string]bool, error) {
+	valid := map[string]bool{"stable": true, "preparingrebalance": true, "completingrebalance": true, "empty": true, "dead": true}
+	states := make(map[string]bool)
+	for _, state := range strings.Split(s, ",") {
+		state = strings.ToLower(strings.TrimSpace(state))
+		if state == "" {
+			continue
+		}
+		if !valid[state] {
+			return nil, fmt.Errorf("消费组状态 %q 无效，可选: Stable, PreparingRebalance, CompletingRebalance, Empty, Dead", state)
+		}
+		states[state] = true
+	}
+	return states, nil
+}
+
+// ListGroupSummaries 获取名称包含keyword的消费组的状态，states不为空时只保留这些状态的消费组，按名称排序
+func ListGroupSummaries(cluster cluster_tools.Cluster, keyword string, states map[string]bool) ([]GroupSummary, error) {
+	groups, err := GetAllConsumerGroups(cluster, keyword)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	desc, err := cluster.Admin().DescribeConsumerGroups(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []GroupSummary
+	for _, d := range desc {
+		if len(states) > 0 && !states[strings.ToLower(d.State)] {
+			continue
+		}
+		summaries = append(summaries, newGroupSummary(d))
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Group < summaries[j].Group })
+	return summaries, nil
+}
+
+// PrintGroupSummaries 打印消费组列表及其状态、协议类型、分配策略和成员数
+func PrintGroupSummaries(summaries []GroupSummary) {
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, s := range summaries {
+		if c := stateColor(s.State); c != nil {
+			rowColors[i] = c
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			s.Group,
+			s.State,
+			orDash(s.ProtocolType),
+			orDash(s.Protocol),
+			fmt.Sprintf("%d", s.Members),
+		})
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"ID", "GROUP", "STATE", "PROTOCOL-TYPE", "STRATEGY", "MEMBERS"}, rows, rowColors)
+}
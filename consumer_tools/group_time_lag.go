@@ -0,0 +1,191 @@
+package consumer_tools
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// 读取单条消息时间戳的超时时间和并发读取的分区数
+const (
+	timestampReadTimeout = 3 * time.Second
+	timestampReadWorkers = 16
+)
+
+// PartitionTimeLag 分区按时间计算的lag和追上最新消息的预计时间
+type PartitionTimeLag struct {
+	PartitionLag
+	// LastConsumed 最后一条已提交消息(offset为Committed-1)的时间戳，零值表示未知
+	LastConsumed time.Time
+	// Newest 最新一条消息(offset为LogEnd-1)的时间戳，零值表示未知
+	Newest time.Time
+	// ConsumeRate 和 ProduceRate 为采样窗口内每秒提交和写入的消息数
+	ConsumeRate float64
+	ProduceRate float64
+	rateOk      bool
+}
+
+// TimeLag 最新消息与最后一条已消费消息的时间差，没有lag时为0
+func (p PartitionTimeLag) TimeLag() (time.Duration, bool) {
+	if p.Lag() == 0 {
+		return 0, true
+	}
+	if p.LastConsumed.IsZero() || p.Newest.IsZero() {
+		return 0, false
+	}
+	if d := p.Newest.Sub(p.LastConsumed); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// ETA 按采样窗口内的净消费速率(消费速率减去生产速率)估算追上最新消息需要的时间，
+// ok为false表示速率未知或消费跟不上生产
+func (p PartitionTimeLag) ETA() (time.Duration, bool) {
+	if p.Lag() == 0 {
+		return 0, true
+	}
+	net := p.ConsumeRate - p.ProduceRate
+	if !p.rateOk || p.Lag() < 0 || net <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(p.Lag()) / net * float64(time.Second)), true
+}
+
+// messageTimestamp 读取分区上指定offset的消息的时间戳
+func messageTimestamp(consumer sarama.Consumer, topic string, partition int32, offset int64) (time.Time, error) {
+	pc, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer pc.Close()
+
+	select {
+	case msg := <-pc.Messages():
+		return msg.Timestamp, nil
+	case <-time.After(timestampReadTimeout):
+		return time.Time{}, fmt.Errorf("读取 %s-%d offset %d 超时", topic, partition, offset)
+	}
+}
+
+// GetConsumerGroupTimeLag 在window内采样两次offset得到消费和生产速率，
+// 再读取每个分区最后一条已消费消息和最新消息的时间戳
+func GetConsumerGroupTimeLag(cluster cluster_tools.Cluster, group, groupTopicKeyword string, window time.Duration) ([]PartitionTimeLag, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("采样窗口必须大于0")
+	}
+	first, err := GetConsumerGroupLag(cluster, group, groupTopicKeyword)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	time.Sleep(window)
+	lags, err := GetConsumerGroupLag(cluster, group, groupTopicKeyword)
+	if err != nil {
+		return nil, err
+	}
+	sample := &lagSample{at: start, lags: make(map[partitionKey]PartitionLag, len(first))}
+	for _, p := range first {
+		sample.lags[partitionKey{p.Topic, p.Partition}] = p
+	}
+	elapsed := time.Since(start).Seconds()
+
+	// 同一个consumer关闭分区后不会立即释放该分区，两次读取分别使用不同的consumer
+	consumedReader, err := sarama.NewConsumerFromClient(cluster.Client())
+	if err != nil {
+		return nil, fmt.Errorf("创建consumer失败: %v", err)
+	}
+	defer consumedReader.Close()
+	newestReader, err := sarama.NewConsumerFromClient(cluster.Client())
+	if err != nil {
+		return nil, fmt.Errorf("创建consumer失败: %v", err)
+	}
+	defer newestReader.Close()
+
+	result := make([]PartitionTimeLag, len(lags))
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, timestampReadWorkers)
+	for i, p := range lags {
+		c := compareSample(sample, p, elapsed)
+		result[i] = PartitionTimeLag{
+			PartitionLag: p,
+			ConsumeRate:  c.consumeRate,
+			ProduceRate:  c.produceRate,
+			rateOk:       c.consumeOk && c.produceOk,
+		}
+		if p.Lag() <= 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(r *PartitionTimeLag) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			// 已提交消息可能已被日志清理删除，读取失败时时间戳保持未知
+			if r.Committed > 0 {
+				r.LastConsumed, _ = messageTimestamp(consumedReader, r.Topic, r.Partition, r.Committed-1)
+			}
+			r.Newest, _ = messageTimestamp(newestReader, r.Topic, r.Partition, r.LogEnd-1)
+		}(&result[i])
+	}
+	wg.Wait()
+	return result, nil
+}
+
+// formatTimestamp 格式化消息时间戳，未知时显示"-"
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// PrintConsumerGroupTimeLag 打印每个分区按时间计算的lag、消费速率和预计追上时间，时间lag最大的分区标黄
+func PrintConsumerGroupTimeLag(group string, lags []PartitionTimeLag, window time.Duration) {
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	maxIndex, maxLag := -1, time.Duration(0)
+	for i, p := range lags {
+		timeLag, eta := "-", "-"
+		if d, ok := p.TimeLag(); ok {
+			timeLag = d.Round(time.Second).String()
+			if d > maxLag {
+				maxIndex, maxLag = i, d
+			}
+		}
+		if d, ok := p.ETA(); ok {
+			eta = d.Round(time.Second).String()
+		} else if p.rateOk && p.Lag() > 0 {
+			eta = "追不上"
+		}
+		lag := "-"
+		if p.Lag() >= 0 {
+			lag = format_tools.FormatIntWithCommas(p.Lag())
+		}
+		rows = append(rows, []string{
+			p.Topic,
+			fmt.Sprintf("%d", p.Partition),
+			lag,
+			formatTimestamp(p.LastConsumed),
+			formatTimestamp(p.Newest),
+			timeLag,
+			formatRate(p.ConsumeRate, p.rateOk),
+			formatRate(p.ProduceRate, p.rateOk),
+			eta,
+		})
+	}
+	if maxIndex >= 0 {
+		rowColors[maxIndex] = color.New(color.FgYellow)
+	}
+	fmt.Printf("消费组: %s    速率采样窗口: %s\n", group, window)
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "LAG", "LAST-CONSUMED", "NEWEST", "TIME-LAG", "CONSUME-RATE", "PRODUCE-RATE", "ETA"}, rows, rowColors)
+	if maxIndex >= 0 {
+		fmt.Printf("时间lag最大的分区: %s-%d，落后 %s\n", lags[maxIndex].Topic, lags[maxIndex].Partition, maxLag.Round(time.Second))
+	}
+}
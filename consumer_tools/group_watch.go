@@ -0,0 +1,217 @@
+package consumer_tools
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/fatih/color"
+)
+
+// partitionKey 用于在两次刷新之间对应同一个分区
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// lagSample 一次刷新得到的各分区offset
+type lagSample struct {
+	at   time.Time
+	lags map[partitionKey]PartitionLag
+}
+
+// lagChange 分区相对上一次刷新的变化，ok为false表示没有上一次的数据或offset未知
+type lagChange struct {
+	lagDelta    int64
+	lagOk       bool
+	consumeRate float64
+	consumeOk   bool
+	produceRate float64
+	produceOk   bool
+}
+
+// compareSample 计算分区相对上一次刷新的lag变化、消费速率和生产速率(条/秒)
+func compareSample(prev *lagSample, p PartitionLag, elapsed float64) lagChange {
+	var c lagChange
+	if prev == nil || elapsed <= 0 {
+		return c
+	}
+	before, ok := prev.lags[partitionKey{p.Topic, p.Partition}]
+	if !ok {
+		return c
+	}
+	if p.Lag() >= 0 && before.Lag() >= 0 {
+		c.lagDelta, c.lagOk = p.Lag()-before.Lag(), true
+	}
+	if p.Committed >= 0 && before.Committed >= 0 {
+		c.consumeRate, c.consumeOk = float64(p.Committed-before.Committed)/elapsed, true
+	}
+	if p.LogEnd >= 0 && before.LogEnd >= 0 {
+		c.produceRate, c.produceOk = float64(p.LogEnd-before.LogEnd)/elapsed, true
+	}
+	return c
+}
+
+// formatLagDelta lag增加显示↑，减少显示↓，不变显示→
+func formatLagDelta(delta int64, ok bool) string {
+	switch {
+	case !ok:
+		return "-"
+	case delta > 0:
+		return "↑" + format_tools.FormatIntWithCommas(delta)
+	case delta < 0:
+		return "↓" + format_tools.FormatIntWithCommas(-delta)
+	default:
+		return "→0"
+	}
+}
+
+// formatRate 格式化每秒消息数，未知时显示"-"
+func formatRate(rate float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f/s", rate)
+}
+
+// lagColor lag增加的行标黄，减少的行标绿
+func lagColor(delta int64, ok bool) *color.Color {
+	switch {
+	case !ok || delta == 0:
+		return nil
+	case delta > 0:
+		return color.New(color.FgYellow)
+	default:
+		return color.New(color.FgGreen)
+	}
+}
+
+// topicTotal 一个topic所有分区的合计
+type topicTotal struct {
+	topic      string
+	partitions int
+	lag        int64
+	change     lagChange
+}
+
+// printWatchTables 打印每个分区和每个topic合计的lag、lag变化和速率
+func printWatchTables(lags []PartitionLag, prev *lagSample, elapsed float64) {
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	var totals []*topicTotal
+	for i, p := range lags {
+		c := compareSample(prev, p, elapsed)
+		if cl := lagColor(c.lagDelta, c.lagOk); cl != nil {
+			rowColors[i] = cl
+		}
+		lag := "-"
+		if p.Lag() >= 0 {
+			lag = format_tools.FormatIntWithCommas(p.Lag())
+		}
+		rows = append(rows, []string{
+			p.Topic,
+			fmt.Sprintf("%d", p.Partition),
+			formatOffset(p.Committed),
+			formatOffset(p.LogEnd),
+			lag,
+			formatLagDelta(c.lagDelta, c.lagOk),
+			formatRate(c.consumeRate, c.consumeOk),
+			formatRate(c.produceRate, c.produceOk),
+			p.member.clientID,
+		})
+
+		// lags已按topic排序，同一个topic的分区是连续的
+		if len(totals) == 0 || totals[len(totals)-1].topic != p.Topic {
+			totals = append(totals, &topicTotal{topic: p.Topic, change: lagChange{lagOk: prev != nil, consumeOk: prev != nil, produceOk: prev != nil}})
+		}
+		t := totals[len(totals)-1]
+		t.partitions++
+		if p.Lag() > 0 {
+			t.lag += p.Lag()
+		}
+		// 只要有一个分区的变化未知，topic合计就显示为未知
+		t.change.lagDelta += c.lagDelta
+		t.change.lagOk = t.change.lagOk && c.lagOk
+		t.change.consumeRate += c.consumeRate
+		t.change.consumeOk = t.change.consumeOk && c.consumeOk
+		t.change.produceRate += c.produceRate
+		t.change.produceOk = t.change.produceOk && c.produceOk
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "CURRENT-OFFSET", "LOG-END-OFFSET", "LAG", "LAG-CHANGE", "CONSUME-RATE", "PRODUCE-RATE", "CLIENT-ID"}, rows, rowColors)
+
+	rows = nil
+	rowColors = make(map[int]*color.Color)
+	for i, t := range totals {
+		if cl := lagColor(t.change.lagDelta, t.change.lagOk); cl != nil {
+			rowColors[i] = cl
+		}
+		rows = append(rows, []string{
+			t.topic,
+			fmt.Sprintf("%d", t.partitions),
+			format_tools.FormatIntWithCommas(t.lag),
+			formatLagDelta(t.change.lagDelta, t.change.lagOk),
+			formatRate(t.change.consumeRate, t.change.consumeOk),
+			formatRate(t.change.produceRate, t.change.produceOk),
+		})
+	}
+	fmt.Println("按topic合计:")
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITIONS", "LAG", "LAG-CHANGE", "CONSUME-RATE", "PRODUCE-RATE"}, rows, rowColors)
+}
+
+// WatchConsumerGroup 每隔interval刷新一次消费组详情，显示相对上一次刷新的lag变化和消费、生产速率，
+// 直到收到Ctrl+C。单次获取失败时打印错误并在下一次继续刷新
+func WatchConsumerGroup(cluster cluster_tools.Cluster, group, groupTopicKeyword string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("刷新间隔必须大于0")
+	}
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigchan)
+
+	var prev *lagSample
+	for {
+		lags, err := GetConsumerGroupLag(cluster, group, groupTopicKeyword)
+		now := time.Now()
+
+		// 清屏并把光标移到左上角
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("刷新间隔: %s    时间: %s    (Ctrl+C退出)\n", interval, now.Format("15:04:05"))
+		// 在header中显示状态，便于观察rebalance
+		if summary, err := DescribeGroupSummary(cluster, group); err == nil {
+			PrintGroupHeader(summary)
+		} else {
+			fmt.Println("消费组:", group)
+			cluster_tools.PrintError("获取消费组状态失败: %v", err)
+		}
+		if err != nil {
+			cluster_tools.PrintError("获取消费组详情失败: %v", err)
+		} else if len(lags) == 0 {
+			color.Yellow("消费组 %s 没有匹配的分区", group)
+		} else {
+			elapsed := 0.0
+			if prev != nil {
+				elapsed = now.Sub(prev.at).Seconds()
+			}
+			printWatchTables(lags, prev, elapsed)
+
+			sample := &lagSample{at: now, lags: make(map[partitionKey]PartitionLag, len(lags))}
+			for _, p := range lags {
+				sample.lags[partitionKey{p.Topic, p.Partition}] = p
+			}
+			prev = sample
+		}
+
+		select {
+		case <-sigchan:
+			fmt.Println()
+			color.Green("✔已停止刷新")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package consumer_tools
+
+import (
+	"fmt"
+	"sort"
+
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// TopicGroup 在topic上有已提交offset的消费组
+type TopicGroup struct {
+	Group   string
+	State   string
+	Members int
+	// Partitions 有已提交offset的分区数
+	Partitions int
+	// Lag 所有分区lag之和，最新offset获取失败的分区不计入
+	Lag int64
+}
+
+// TopicConsumerGroups 查找在topic上有已提交offset的所有消费组，按消费组名称排序
+func TopicConsumerGroups(cluster cluster_tools.Cluster, topic string) ([]TopicGroup, error) {
+	client := cluster.Client()
+	admin := cluster.Admin()
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("获取topic %s 的分区失败: %v", topic, err)
+	}
+	logEnd := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		if offset, err := client.GetOffset(topic, p, sarama.OffsetNewest); err == nil {
+			logEnd[p] = offset
+		}
+	}
+
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	var result []TopicGroup
+	for _, group := range names {
+		offsets, err := admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+		if err != nil {
+			color.Yellow("获取消费组 %s 的offset失败: %v", group, cluster_tools.MaskError(err))
+			continue
+		}
+		tg := TopicGroup{Group: group, State: "?"}
+		for _, p := range partitions {
+			block := offsets.GetBlock(topic, p)
+			if block == nil || block.Offset < 0 {
+				continue
+			}
+			tg.Partitions++
+			if end, ok := logEnd[p]; ok && end > block.Offset {
+				tg.Lag += end - block.Offset
+			}
+		}
+		if tg.Partitions > 0 {
+			result = append(result, tg)
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	matched := make([]string, len(result))
+	for i, tg := range result {
+		matched[i] = tg.Group
+	}
+	// 获取状态失败时仍返回已统计的offset和lag，状态显示为"?"
+	desc, err := admin.DescribeConsumerGroups(matched)
+	if err != nil {
+		color.Yellow("获取消费组状态失败: %v", cluster_tools.MaskError(err))
+		return result, nil
+	}
+	states := make(map[string]int, len(desc))
+	for i, d := range desc {
+		states[d.GroupId] = i
+	}
+	for i := range result {
+		j, ok := states[result[i].Group]
+		if !ok {
+			continue
+		}
+		if desc[j].Err != sarama.ErrNoError {
+			color.Yellow("获取消费组 %s 的状态失败: %v", result[i].Group, desc[j].Err)
+			continue
+		}
+		result[i].State = desc[j].State
+		result[i].Members = len(desc[j].Members)
+	}
+	return result, nil
+}
+
+// PrintTopicConsumerGroups 打印消费topic的消费组，没有成员但还有lag的消费组标黄
+func PrintTopicConsumerGroups(topic string, groups []TopicGroup) {
+	if len(groups) == 0 {
+		color.Yellow("没有消费组在topic %s 上提交过offset", topic)
+		return
+	}
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, tg := range groups {
+		if tg.Members == 0 && tg.Lag > 0 {
+			rowColors[i] = color.New(color.FgYellow)
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			tg.Group,
+			tg.State,
+			fmt.Sprintf("%d", tg.Members),
+			fmt.Sprintf("%d", tg.Partitions),
+			format_tools.FormatIntWithCommas(tg.Lag),
+		})
+	}
+	fmt.Printf("消费topic %s 的消费组:\n", topic)
+	format_tools.PrintPrettyTableWithColors([]string{"ID", "GROUP", "STATE", "MEMBERS", "PARTITIONS", "LAG"}, rows, rowColors)
+	fmt.Printf("共 %d 个消费组\n", len(groups))
+}
@@ -0,0 +1,24 @@
+package format_tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseKeyValues 解析"k1=v1,k2=v2"格式的配置项，值中可以包含"="
+func ParseKeyValues(s string) (map[string]string, error) {
+	entries := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("配置项格式错误，应为 key=value: %s", item)
+		}
+		entries[key] = strings.TrimSpace(parts[1])
+	}
+	return entries, nil
+}
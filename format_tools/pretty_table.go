@@ -3,10 +3,17 @@ package format_tools
 import (
 	"fmt"
 	"strings"
+
+	"github.com/fatih/color"
 )
 
 // 打印类似python prettytable的表格
 func PrintPrettyTable(headers []string, rows [][]string) {
+	PrintPrettyTableWithColors(headers, rows, nil)
+}
+
+// PrintPrettyTableWithColors 与PrintPrettyTable相同，rowColors中指定的行按对应颜色输出，用于突出异常数据
+func PrintPrettyTableWithColors(headers []string, rows [][]string, rowColors map[int]*color.Color) {
 	// 计算每列最大宽度
 	colWidths := make([]int, len(headers))
 	for i, h := range headers {
@@ -35,13 +42,16 @@ func PrintPrettyTable(headers []string, rows [][]string) {
 	fmt.Println()
 	fmt.Println(sep)
 
-	// 打印内容
-	for _, row := range rows {
-		fmt.Print("|")
+	// 打印内容，先按原始文本对齐再上色，避免颜色控制符影响列宽
+	for r, row := range rows {
+		line := "|"
 		for i, cell := range row {
-			fmt.Printf(" %-*s |", colWidths[i], cell)
+			line += fmt.Sprintf(" %-*s |", colWidths[i], cell)
+		}
+		if c, ok := rowColors[r]; ok && c != nil {
+			line = c.Sprint(line)
 		}
-		fmt.Println()
+		fmt.Println(line)
 	}
 	fmt.Println(sep)
 }
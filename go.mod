@@ -6,7 +6,8 @@ require (
 	github.com/IBM/sarama v1.45.2
 	github.com/fatih/color v1.18.0
 	github.com/manifoldco/promptui v0.9.0
-	github.com/segmentio/kafka-go v0.4.48
+	github.com/xdg-go/scram v1.1.2
+	golang.org/x/term v0.32.0
 )
 
 require (
@@ -30,7 +31,6 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
-	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	golang.org/x/crypto v0.38.0 // indirect
 	golang.org/x/net v0.40.0 // indirect
@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"kafka_dog/advanced_tools"
+	"kafka_dog/broker_tools"
+	"kafka_dog/cluster_tools"
 	"kafka_dog/consumer_tools"
 	"kafka_dog/format_tools"
 	"kafka_dog/topic_tools"
@@ -28,231 +32,571 @@ Kafka Dog - Kafka命令行工具
   不添加任何选项进入命令行选择模式
 
 常用选项:
-  -host ip:port            Kafka地址, 只加host参数则测试连接情况
+  -host ip:port[,ip:port]  Kafka地址, 多个bootstrap地址用逗号分隔, IPv6格式为[::1]:9092, 只加host参数则测试连接情况
   -topic-list              查看Kafka topic，可使用-topic-keyword过滤
   -topic-name str		   输入topic名称查看详细信息，该参数会覆盖-topic-keyword参数(支持在命令行选择模式中使用)
-  -topic-detail            选择某个topic查看详细信息，可使用-topic-keyword过滤
+  -topic-detail            选择某个topic查看详细信息，可使用-topic-keyword过滤，在命令行选择模式中查看后可继续查看消费该topic的消费组
+  -topic-groups            查看在某个topic上提交过offset的消费组及其状态、成员数和总lag，通过-topic-name或-topic-keyword选择topic
   -topic-keyword str       查看包含某个关键词的topic
-  -group-list              查看所有Kafka消费组，可使用-group-keyword过滤
+  -topic-create            创建-topic-name指定的topic，可配合-partitions、-replication-factor、-topic-config、-validate-only
+  -partitions int          分区数，默认使用broker的num.partitions(需要-kafka-version 2.4.0及以上)
+  -replication-factor int  副本数，默认使用broker的default.replication.factor(需要-kafka-version 2.4.0及以上)
+  -topic-config str        topic配置，格式为key=value,key=value，如retention.ms=86400000,cleanup.policy=compact
+  -validate-only           只让broker校验参数，不实际执行(dry run)
+  -topic-delete            删除topic，通过-topic-name(精确)、-topic-keyword(关键词)或-topic-regex(正则)匹配，删除前预览并要求输入确认
+  -topic-regex str         按正则表达式匹配topic，关键词和正则都不会匹配__开头的内部topic
+  -yes                     跳过删除等操作的输入确认，用于脚本
+  -topic-configs           查看topic配置的值和来源(default/static/dynamic override)
+  -topic-alter-config      修改topic配置，配合-config-set、-config-delete、-config-append，修改后显示前后差异
+  -config-set str          要设置的配置，格式为key=value,key=value
+  -config-delete str       要删除(恢复为上级配置)的配置名，多个用逗号分隔
+  -config-append str       给列表类型的配置追加值，格式为key=value
+  -topic-add-partitions    把topic的分区数增加到-partitions，执行前采样key分析murmur2默认分区器下会改变分区的key比例
+  -sample-size int         增加分区前每个分区采样的消息数，默认1000
+  -leader-report           查看每个broker的leader数与优先leader数，并列出leader不是优先副本的分区，
+                           可用-topic-name/-topic-keyword/-topic-regex限定topic，默认所有topic
+  -elect-leaders           对leader不是优先副本的分区触发优先副本选举，topic范围同-leader-report
+  -election-type str       选举类型: preferred(默认)或unclean，unclean只对没有leader的分区生效，可能丢失数据
+  -reassign-generate       为-topic-name/-topic-keyword/-topic-regex匹配的topic生成把副本均衡分布到-target-brokers的重分配计划，
+                           计划写入-reassign-file，当前分配写入同名的.rollback.json文件用于回滚
+  -reassign-execute        执行-reassign-file中的重分配计划，预览并确认后提交，然后跟踪进度直到完成
+  -reassign-status         跟踪重分配进度，指定的-reassign-file存在时只看计划中的分区，完成后移除限流
+  -reassign-cancel         取消正在进行的重分配，指定的-reassign-file存在时只取消计划中的分区
+  -target-brokers str      重分配的目标broker ID，多个用逗号分隔，如1,2,3
+  -reassign-file str       重分配计划文件，格式与kafka-reassign-partitions.sh兼容，默认reassignment.json
+  -throttle int            重分配时副本同步的限流速率(字节/秒)，默认不限流，完成或取消后自动移除
+  -interval int            跟踪进度和-watch的刷新间隔(秒)，也是-time-lag的速率采样窗口，默认5
+  -group-list              查看所有Kafka消费组及其状态、分配策略和成员数，可使用-group-keyword过滤
+  -group-state str         只列出指定状态的消费组(只支持与-group-list一起使用)，多个用逗号分隔，不区分大小写，
+                           可选Stable、PreparingRebalance、CompletingRebalance、Empty、Dead
   -group-detail            查看某个消费组的详细信息，可使用-group-keyword过滤
   -group-name str          输入消费组名称查看详细信息，该参数会覆盖-group-keyword参数(支持在命令行选择模式中使用，会覆盖关键字参数)
   -group-keyword str       查看包含某个关键词的消费组
   -group-topic-keyword str 查看消费组包含某个关键词的topic(只支持与-group-detail一起使用)(支持在命令行选择模式中使用)
+  -time-lag                与-group-detail一起使用，显示每个分区最后一条已消费消息与最新消息的时间差，
+                           并按-interval秒采样窗口内的消费和生产速率估算追上的时间
+  -watch                   与-group-detail一起使用，每隔-interval秒刷新一次，显示lag变化、消费和生产速率及topic合计，Ctrl+C退出
+  -group-reset-offsets     重置消费组的offset，先预览当前和重置后的offset，确认后执行，消费组有活跃成员时拒绝执行，
+                           配合-validate-only只预览不执行
+  -reset-strategy str      重置策略: earliest、latest、datetime、shift、offset、file
+  -reset-value str         策略参数: datetime为时间(如"2024-01-02 15:04:05")，shift为偏移的消息数(可为负数)，
+                           offset为目标offset，file为文件路径(每行topic,partition,offset)，同时指定-topic-name或-reset-partitions时只重置文件中匹配的分区
+  -reset-partitions str    只重置-topic-name的这些分区，多个用逗号分隔；不指定-topic-name时重置所有已提交过offset的topic
+  -group-delete            删除消费组，通过-group-name(精确)、-group-keyword(关键词)或-group-regex(正则)匹配，删除前预览并要求输入确认
+  -group-regex str         按正则表达式匹配消费组
+  -group-delete-offsets    通过OffsetDelete API删除消费组在-topic-name上已提交的offset，用于服务不再消费某个topic
   -from-beginning int      选择某个topic，从头消费N条消息，可使用-topic-keyword过滤
   -from-latest             选择某个topic，从最新消费消息，可使用-topic-keyword过滤
+  -diagnose                诊断连接：获取元数据后逐个检查broker广播地址的DNS、TCP、TLS、SASL认证和API请求
+  -broker-list             查看broker列表：ID、地址、机架、controller、advertised.listeners、分区数、leader数和支持的API版本范围
+  -broker-configs          查看-broker-id的生效配置、来源和同义配置(各级别的取值)
+  -broker-alter-config     修改-broker-id的动态配置，配合-config-set、-config-delete，修改前预览差异并要求确认
+  -broker-id str           broker ID，default表示对所有broker生效的集群默认动态配置
+  -dynamic-only            只显示动态配置(支持-broker-configs)
+  -log-dirs                查看磁盘占用：每个broker日志目录的大小、占用最大的topic，以及正在迁移的future副本和offset差距大的副本，
+                           指定-topic-name/-topic-keyword/-topic-regex时只统计这些topic并列出每个分区副本的大小
+  -top int                 -log-dirs显示占用最大的topic数量，默认10
+  -lag-threshold int       -log-dirs中副本offset差距超过该值时标记，默认10000
+  -json                    以JSON格式输出(支持-broker-list)，连接过程的提示和错误输出到标准错误
+  -health                  集群健康检查：列出副本不足、没有leader和ISR少于min.insync.replicas的分区并按broker汇总，
+                           发现问题时以退出码2退出，参数错误、凭据读取失败或无法连接集群时以退出码1退出，可用于监控脚本
   -sha-256                 是否启用SHA-256连接
   -sha-512                 是否启用SHA-512连接
+  -sasl-plain              是否启用SASL/PLAIN连接
+  -oauthbearer             是否启用SASL/OAUTHBEARER连接，需配合-oauth-token-file或-oauth-token-cmd
+  -oauth-token-file str    OAUTHBEARER token文件路径，每次认证时重新读取
+  -oauth-token-cmd str     获取OAUTHBEARER token的命令，命令的标准输出即为token
   -usr str                 Kafka 认证用户名
-  -pwd str                 Kafka 认证密码
+  -pwd str                 Kafka 认证密码(会留在shell历史和进程列表中，建议使用下面的方式)
+  -pwd-file str            从文件读取Kafka认证密码
+  -pwd-cmd str             凭据命令，执行后从标准输出读取Kafka认证密码
+                           也可通过环境变量KAFKA_DOG_USERNAME/KAFKA_DOG_PASSWORD提供用户名和密码，
+                           都未提供时在终端中不回显地输入密码
+  -tls                     是否启用TLS连接(SSL/SASL_SSL监听)，可与-sha-256/-sha-512组合使用
+  -tls-ca str              CA证书路径，不指定则使用系统证书
+  -tls-cert str            客户端证书路径(双向TLS)，需与-tls-key一起使用
+  -tls-key str             客户端私钥路径(双向TLS)，需与-tls-cert一起使用
+  -tls-server-name str     覆盖证书校验使用的服务器名称
+  -tls-insecure            跳过服务器证书校验(仅用于测试环境)
+  -kafka-version str       Kafka集群版本，如2.8.0，默认按2.1.0协议通信。-topic-alter-config、-broker-alter-config需要2.3.0及以上，
+                           -reassign-execute/-reassign-status/-reassign-cancel、-throttle、-group-delete-offsets、
+                           使用broker默认分区数和副本数创建topic、unclean选举需要2.4.0及以上，请指定集群的实际版本
+  -client-id str           连接使用的client.id，默认kafka_dog
+  -timeout int             连接和读写超时时间(秒)，默认10
+  -config str              集群配置文件路径，默认~/.kafka_dog/config.json，也可通过环境变量KAFKA_DOG_CONFIG指定
+  -profile str             使用配置文件中指定名称的集群，不指定-host和-profile时使用当前默认集群
+  -profile-list            查看配置文件中的集群
+  -use-profile str         设置默认使用的集群(current context)
+  -save-profile str        把当前连接参数保存为指定名称的集群配置
   -h, --help               显示帮助信息
 
 示例:
 kafka_dog
 kafka_dog -host 127.0.0.1:9092 -topic-list
 kafka_dog -host 127.0.0.1:9092 -group-list
+kafka_dog -host 127.0.0.1:9092 -group-list -group-state PreparingRebalance,CompletingRebalance
+kafka_dog -host 10.0.0.1:9092,10.0.0.2:9092,[::1]:9092 -topic-list
+kafka_dog -host 10.0.0.1:9092 -diagnose
+kafka_dog -profile prod -health
+kafka_dog -profile prod -broker-list -json
+kafka_dog -profile prod -log-dirs -top 20
+kafka_dog -profile prod -broker-alter-config -broker-id default -config-set log.cleaner.threads=2
+kafka_dog -profile prod -elect-leaders -topic-keyword orders
+kafka_dog -profile prod -topic-groups -topic-name orders
 kafka_dog -host 127.0.0.1:9092 -sha-256 -usr admin -pwd 123456 -topic-list
+kafka_dog -host 127.0.0.1:9092 -sasl-plain -usr admin -pwd 123456 -group-list
+kafka_dog -host 127.0.0.1:9093 -tls -oauthbearer -oauth-token-cmd "get-token.sh" -topic-list
+kafka_dog -host 127.0.0.1:9093 -tls -tls-ca ca.pem -tls-cert client.pem -tls-key client.key -topic-list
 kafka_dog -group-detail -group-name test -group-topic-keyword topicName
+kafka_dog -group-detail -group-name test -watch -interval 10
+kafka_dog -group-detail -group-name test -time-lag
+kafka_dog -host 127.0.0.1:9092 -group-reset-offsets -group-name test -topic-name orders -reset-strategy datetime -reset-value "2024-01-02 15:04:05"
+kafka_dog -host 127.0.0.1:9092 -group-delete -group-regex "^tmp-.*"
+kafka_dog -host 127.0.0.1:9092 -group-delete-offsets -group-name test -topic-name orders
+kafka_dog -host 127.0.0.1:9092 -group-reset-offsets -group-name test -reset-strategy shift -reset-value -1000 -validate-only
+kafka_dog -host 127.0.0.1:9092 -topic-delete -topic-regex "^test-.*"
+kafka_dog -host 127.0.0.1:9092 -topic-alter-config -topic-name orders -config-set retention.ms=3600000 -config-delete cleanup.policy
+kafka_dog -host 127.0.0.1:9092 -topic-add-partitions -topic-name orders -partitions 12
+kafka_dog -host 127.0.0.1:9092 -reassign-generate -topic-keyword orders -target-brokers 1,2,3 -reassign-file orders.json
+kafka_dog -host 127.0.0.1:9092 -reassign-execute -reassign-file orders.json -throttle 50000000
+kafka_dog -host 127.0.0.1:9092 -topic-create -topic-name orders -partitions 6 -replication-factor 3 -topic-config retention.ms=86400000
+kafka_dog -host 10.0.0.1:9093 -tls -sha-512 -usr admin -pwd 123456 -save-profile prod
+kafka_dog -profile prod -topic-list
+kafka_dog -use-profile prod
 
 `)
 	}
 
-	host := flag.String("host", "", "Kafka地址, 只加host参数则测试连接情况")
+	host := flag.String("host", "", "Kafka地址, 多个地址用逗号分隔, 只加host参数则测试连接情况")
 
 	listTopics := flag.Bool("topic-list", false, "查看Kafka topic，可使用-topic-keyword参数过滤")
 	topicName := flag.String("topic-name", "", "输入topic名称查看详细信息")
 	topicDetail := flag.Bool("topic-detail", false, "选择某个topic查看详细信息")
+	topicGroups := flag.Bool("topic-groups", false, "查看消费某个topic的消费组")
 	topicKeyword := flag.String("topic-keyword", "", "查看包含某个关键词的topic")
 
 	listConsumerGroups := flag.Bool("group-list", false, "查看所有Kafka消费组")
 	consumerGroupsDetail := flag.Bool("group-detail", false, "查看某个消费组的详细信息")
 	groupName := flag.String("group-name", "", "查看输入名称的消费组")
 	groupKeyword := flag.String("group-keyword", "", "查看包含某个关键词的消费组")
+	groupState := flag.String("group-state", "", "只列出指定状态的消费组，多个用逗号分隔")
 	groupTopicKeyword := flag.String("group-topic-keyword", "", "查看消费组包含某个关键词的topic")
+	watch := flag.Bool("watch", false, "持续刷新消费组详情")
+	timeLag := flag.Bool("time-lag", false, "显示按时间计算的lag和预计追上时间")
+
+	groupResetOffsets := flag.Bool("group-reset-offsets", false, "重置消费组的offset")
+	resetStrategy := flag.String("reset-strategy", "", "重置策略: earliest、latest、datetime、shift、offset、file")
+	resetValue := flag.String("reset-value", "", "重置策略的参数")
+	resetPartitions := flag.String("reset-partitions", "", "只重置这些分区，多个用逗号分隔")
+
+	groupDelete := flag.Bool("group-delete", false, "删除消费组")
+	groupRegex := flag.String("group-regex", "", "按正则表达式匹配消费组")
+	groupDeleteOffsets := flag.Bool("group-delete-offsets", false, "删除消费组在某个topic上的offset")
 
 	testConsumeFromBeginning := flag.Int("from-beginning", 0, "选择某个topic，从头消费N条消息")
 	testConsumeFromLatest := flag.Bool("from-latest", false, "选择某个topic，从最新消费消息")
 
-	// 如果需要TLS连接，可以添加相关参数
+	diagnose := flag.Bool("diagnose", false, "诊断broker广播地址的连通性")
+	brokerList := flag.Bool("broker-list", false, "查看broker列表")
+	brokerConfigs := flag.Bool("broker-configs", false, "查看broker配置")
+	brokerAlterConfig := flag.Bool("broker-alter-config", false, "修改broker动态配置")
+	brokerID := flag.String("broker-id", "", "broker ID，default表示集群默认配置")
+	dynamicOnly := flag.Bool("dynamic-only", false, "只显示动态配置")
+	logDirs := flag.Bool("log-dirs", false, "查看磁盘占用")
+	top := flag.Int("top", 10, "显示占用最大的topic数量")
+	lagThreshold := flag.Int64("lag-threshold", 10000, "副本offset差距超过该值时标记")
+	jsonOutput := flag.Bool("json", false, "以JSON格式输出")
+	health := flag.Bool("health", false, "集群健康检查，发现异常分区时以退出码2退出")
+
+	// leader均衡
+	leaderReport := flag.Bool("leader-report", false, "查看leader分布和不是优先副本的分区")
+	electLeaders := flag.Bool("elect-leaders", false, "触发leader选举")
+	electionType := flag.String("election-type", "preferred", "选举类型: preferred或unclean")
+
+	// 分区重分配
+	reassignGenerate := flag.Bool("reassign-generate", false, "生成分区重分配计划")
+	reassignExecute := flag.Bool("reassign-execute", false, "执行分区重分配计划")
+	reassignStatus := flag.Bool("reassign-status", false, "跟踪分区重分配进度")
+	reassignCancel := flag.Bool("reassign-cancel", false, "取消正在进行的分区重分配")
+	targetBrokers := flag.String("target-brokers", "", "重分配的目标broker ID，多个用逗号分隔")
+	reassignFile := flag.String("reassign-file", "reassignment.json", "重分配计划文件")
+	throttle := flag.Int("throttle", 0, "重分配时副本同步的限流速率(字节/秒)")
+	interval := flag.Int("interval", 5, "跟踪进度和-watch的刷新间隔(秒)")
+
+	// topic管理
+	topicCreate := flag.Bool("topic-create", false, "创建-topic-name指定的topic")
+	partitions := flag.Int("partitions", -1, "分区数，-1表示使用broker默认值")
+	replicationFactor := flag.Int("replication-factor", -1, "副本数，-1表示使用broker默认值")
+	topicConfigs := flag.String("topic-config", "", "topic配置，格式为key=value,key=value")
+	validateOnly := flag.Bool("validate-only", false, "只让broker校验参数，不实际执行")
+	topicDelete := flag.Bool("topic-delete", false, "删除topic，通过-topic-name、-topic-keyword或-topic-regex指定")
+	topicRegex := flag.String("topic-regex", "", "按正则表达式匹配topic")
+	assumeYes := flag.Bool("yes", false, "跳过确认，用于脚本")
+	topicConfigsDescribe := flag.Bool("topic-configs", false, "查看topic配置及其来源")
+	topicAlterConfig := flag.Bool("topic-alter-config", false, "修改topic配置")
+	configSet := flag.String("config-set", "", "要设置的配置，格式为key=value,key=value")
+	configDelete := flag.String("config-delete", "", "要删除(恢复默认)的配置名，多个用逗号分隔")
+	configAppend := flag.String("config-append", "", "要追加值的列表配置，格式为key=value")
+	topicAddPartitions := flag.Bool("topic-add-partitions", false, "把topic的分区数增加到-partitions")
+	sampleSize := flag.Int("sample-size", 1000, "增加分区前每个分区采样的消息数")
+
 	sha256Enabled := flag.Bool("sha-256", false, "是否启用SHA-256连接")
 	sha512Enabled := flag.Bool("sha-512", false, "是否启用SHA-512连接")
+	plainEnabled := flag.Bool("sasl-plain", false, "是否启用SASL/PLAIN连接")
+	oauthEnabled := flag.Bool("oauthbearer", false, "是否启用SASL/OAUTHBEARER连接")
 
-	// SASL/SCRAM-SHA-256认证相关参数
+	// SASL/SCRAM和SASL/PLAIN认证相关参数
 	username := flag.String("usr", "", "Kafka 认证用户名")
 	password := flag.String("pwd", "", "Kafka 认证密码")
+	passwordFile := flag.String("pwd-file", "", "从文件读取Kafka认证密码")
+	passwordCmd := flag.String("pwd-cmd", "", "凭据命令，从标准输出读取Kafka认证密码")
+
+	// SASL/OAUTHBEARER token来源
+	oauthTokenFile := flag.String("oauth-token-file", "", "OAUTHBEARER token文件路径")
+	oauthTokenCmd := flag.String("oauth-token-cmd", "", "获取OAUTHBEARER token的命令")
+
+	// TLS/双向TLS相关参数，可以单独使用也可以和SASL认证组合
+	var tlsOpts cluster_tools.TLSOptions
+	flag.BoolVar(&tlsOpts.Enabled, "tls", false, "是否启用TLS连接")
+	flag.StringVar(&tlsOpts.CAFile, "tls-ca", "", "CA证书路径")
+	flag.StringVar(&tlsOpts.CertFile, "tls-cert", "", "客户端证书路径")
+	flag.StringVar(&tlsOpts.KeyFile, "tls-key", "", "客户端私钥路径")
+	flag.StringVar(&tlsOpts.ServerName, "tls-server-name", "", "覆盖证书校验使用的服务器名称")
+	flag.BoolVar(&tlsOpts.InsecureSkipVerify, "tls-insecure", false, "跳过服务器证书校验")
+
+	// 客户端调优参数
+	var clientOpts cluster_tools.ClientOptions
+	flag.StringVar(&clientOpts.KafkaVersion, "kafka-version", "", "Kafka集群版本，如2.8.0")
+	flag.StringVar(&clientOpts.ClientID, "client-id", "", "连接使用的client.id")
+	flag.IntVar(&clientOpts.TimeoutSec, "timeout", 0, "连接和读写超时时间(秒)")
+
+	// 集群配置文件(类似kubectl的context)
+	configPath := flag.String("config", cluster_tools.DefaultProfilePath(), "集群配置文件路径")
+	profileName := flag.String("profile", "", "使用配置文件中指定名称的集群")
+	listProfiles := flag.Bool("profile-list", false, "查看配置文件中的集群")
+	useProfile := flag.String("use-profile", "", "设置默认使用的集群")
+	saveProfile := flag.String("save-profile", "", "把当前连接参数保存为指定名称的集群配置")
 
 	flag.Parse()
 
-	if *host == "" {
-		advanced_tools.InputInCmd(host, sha256Enabled, sha512Enabled, username, password, listTopics, topicDetail,
-			listConsumerGroups, consumerGroupsDetail, topicKeyword, groupKeyword, testConsumeFromLatest, groupTopicKeyword, topicName, groupName)
+	// JSON输出时标准输出只保留JSON，连接过程的提示(配置文件、密码警告、端口检查)和所有错误都写到标准错误
+	var messageOutput io.Writer = os.Stdout
+	if *jsonOutput {
+		messageOutput = os.Stderr
+		color.Output = os.Stderr
 	}
 
-	if !advanced_tools.ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail,
-		topicKeyword, groupKeyword, testConsumeFromBeginning, testConsumeFromLatest,
-		sha256Enabled, sha512Enabled, username, password, groupTopicKeyword) {
+	profileConfig, err := cluster_tools.LoadProfiles(*configPath)
+	if err != nil {
+		cluster_tools.PrintError("%v", err)
+		os.Exit(1)
+	}
+
+	if *listProfiles {
+		printProfiles(profileConfig)
 		return
 	}
 
-	portOpen := advanced_tools.CheckPort(*host, 10) // 检查端口是否开放，默认Kafka端口为9092
-	if !portOpen {
-		color.Red("端口未开放或连接失败，请检查Kafka地址和端口是否正确")
+	if *useProfile != "" {
+		if _, err := profileConfig.Get(*useProfile); err != nil {
+			cluster_tools.PrintError("%v", err)
+			os.Exit(1)
+		}
+		profileConfig.CurrentContext = *useProfile
+		if err := profileConfig.Save(*configPath); err != nil {
+			cluster_tools.PrintError("保存配置文件失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("✔已切换当前集群配置为: %s", *useProfile)
 		return
-	} else {
-		color.Green("✔端口已开放，连接成功")
 	}
 
-	// 指定 Kafka broker 地址
-	brokers := []string{*host} // 替换为你的 Kafka 地址
-	fmt.Println("正在连接kafka地址:", *host)
+	// 没有指定地址和profile但带了操作参数时，使用配置文件中的当前集群
+	if *host == "" && *profileName == "" && flag.NFlag() > 0 {
+		*profileName = profileConfig.CurrentContext
+	}
 
-	if *sha256Enabled {
-		if *username == "" || *password == "" {
-			color.Red("启用SASL/SCRAM-SHA-256认证时，必须提供用户名和密码")
-			return
-		} else {
-			ssl_type := "SASL/SCRAM-SHA-256"
-			if !advanced_tools.CheckBrokerConnectionSHA(ssl_type, *host, *username, *password) {
-				color.Red("连接kafka地址失败，请检查地址、用户名和密码是否正确")
-				return
-			} else {
-				color.Green("✔连接SHA-256认证kafka地址成功")
-				sha_ops(brokers, *username, *password, listTopics, topicDetail,
-					listConsumerGroups, consumerGroupsDetail, topicKeyword, groupKeyword,
-					testConsumeFromBeginning, testConsumeFromLatest, &ssl_type)
-			}
+	// 只有命令行选择模式在查看topic详情后询问是否继续查看消费组，带参数运行时不等待输入
+	askTopicGroups := false
+	if *host == "" && *profileName == "" {
+		advanced_tools.InputInCmd(host, profileConfig.Names(), profileName, sha256Enabled, sha512Enabled, plainEnabled, oauthEnabled, username, password,
+			oauthTokenFile, oauthTokenCmd, &tlsOpts, listTopics, topicDetail,
+			listConsumerGroups, consumerGroupsDetail, diagnose, topicCreate, topicDelete, topicConfigsDescribe, topicAlterConfig, topicAddPartitions, health,
+			reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups,
+			topicKeyword, groupKeyword, testConsumeFromLatest, groupTopicKeyword, topicName, groupName)
+		if *topicCreate {
+			advanced_tools.InputTopicCreate(topicName, partitions, replicationFactor, topicConfigs, validateOnly)
+		}
+		if *consumerGroupsDetail {
+			advanced_tools.InputGroupDetailMode(watch, timeLag, interval)
+		}
+		askTopicGroups = *topicDetail
+		if *groupResetOffsets {
+			advanced_tools.InputOffsetReset(groupName, groupKeyword, topicName, resetPartitions, resetStrategy, resetValue)
+		}
+		if *brokerConfigs || *brokerAlterConfig {
+			advanced_tools.InputBrokerID(brokerID)
+		}
+		if *topicAlterConfig || *brokerAlterConfig {
+			advanced_tools.InputConfigChanges(configSet, configDelete, configAppend)
+		}
+		if *topicAddPartitions {
+			advanced_tools.InputPartitionCount(partitions)
 		}
+		if *reassignGenerate || *reassignExecute || *reassignStatus || *reassignCancel {
+			advanced_tools.InputReassign(*reassignGenerate, *reassignExecute, targetBrokers, reassignFile, throttle)
+		}
+	}
+
+	if !advanced_tools.ParameterCheck(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail,
+		diagnose, topicCreate, topicDelete, topicConfigsDescribe, topicAlterConfig, topicAddPartitions, health,
+		reassignGenerate, reassignExecute, reassignStatus, reassignCancel, leaderReport, electLeaders, brokerList, brokerConfigs, brokerAlterConfig, logDirs, groupResetOffsets, groupDelete, groupDeleteOffsets, topicGroups,
+		topicKeyword, groupKeyword, testConsumeFromBeginning, testConsumeFromLatest,
+		sha256Enabled, sha512Enabled, plainEnabled, oauthEnabled, username, password, groupTopicKeyword, groupState, watch, timeLag) {
+		os.Exit(1)
+	}
+
+	authType := cluster_tools.AuthPlaintext
+	if *sha256Enabled {
+		authType = cluster_tools.AuthScramSHA256
 	} else if *sha512Enabled {
-		if *username == "" || *password == "" {
-			color.Red("启用SASL/SCRAM-SHA-512认证时，必须提供用户名和密码")
-			return
-		} else {
-			ssl_type := "SASL/SCRAM-SHA-512"
-			if !advanced_tools.CheckBrokerConnectionSHA(ssl_type, *host, *username, *password) {
-				color.Red("连接kafka地址失败，请检查地址、用户名和密码是否正确")
-				return
-			} else {
-				color.Green("✔连接SHA-512认证kafka地址成功")
-				sha_ops(brokers, *username, *password, listTopics, topicDetail,
-					listConsumerGroups, consumerGroupsDetail, topicKeyword, groupKeyword,
-					testConsumeFromBeginning, testConsumeFromLatest, &ssl_type)
-			}
+		authType = cluster_tools.AuthScramSHA512
+	} else if *plainEnabled {
+		authType = cluster_tools.AuthPlain
+	} else if *oauthEnabled {
+		authType = cluster_tools.AuthOAuthBearer
+	}
+
+	opts := cluster_tools.Options{
+		Brokers:  cluster_tools.ParseBrokers(*host),
+		AuthType: authType,
+		Username: *username,
+		Password: *password,
+		// 未直接提供密码时从密码文件或凭据命令读取
+		PasswordFile: *passwordFile,
+		PasswordCmd:  *passwordCmd,
+		// OAUTHBEARER每次认证时按需读取token
+		OAuthTokenFile: *oauthTokenFile,
+		OAuthTokenCmd:  *oauthTokenCmd,
+		TLS:            tlsOpts,
+		Client:         clientOpts,
+	}
+	if *profileName != "" {
+		profile, err := profileConfig.Get(*profileName)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			os.Exit(1)
+		}
+		opts = cluster_tools.MergeProfile(opts, profile)
+		fmt.Fprintln(messageOutput, "使用集群配置:", *profileName)
+	}
+
+	if len(opts.Brokers) == 0 {
+		cluster_tools.PrintError("未指定Kafka地址，请使用-host或-profile参数")
+		os.Exit(1)
+	}
+
+	if *saveProfile != "" {
+		profileConfig.Profiles[*saveProfile] = cluster_tools.ProfileFromOptions(opts)
+		if profileConfig.CurrentContext == "" {
+			profileConfig.CurrentContext = *saveProfile
 		}
+		if err := profileConfig.Save(*configPath); err != nil {
+			cluster_tools.PrintError("保存配置文件失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("✔已保存集群配置 %s 到 %s", *saveProfile, *configPath)
+		if opts.Password != "" {
+			color.Yellow("注意: 密码以明文保存在配置文件中，建议改用password_file或password_cmd")
+		}
+	}
+
+	if opts.AuthType == cluster_tools.AuthOAuthBearer {
+		if opts.OAuthTokenFile == "" && opts.OAuthTokenCmd == "" {
+			cluster_tools.PrintError("启用%s认证时，必须提供-oauth-token-file或-oauth-token-cmd", opts.AuthType)
+			os.Exit(1)
+		}
+	} else if opts.AuthType != cluster_tools.AuthPlaintext {
+		if *password != "" {
+			color.Yellow("提示: -pwd 会把密码留在shell历史和进程列表中，建议使用-pwd-file、-pwd-cmd或环境变量%s", cluster_tools.EnvPassword)
+		}
+		opts, err = cluster_tools.ResolveCredentials(opts)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			os.Exit(1)
+		}
+		if opts.Username != "" && opts.Password == "" {
+			advanced_tools.InputPassword(&opts.Password)
+			cluster_tools.RegisterSecret(opts.Password)
+		}
+		if opts.Username == "" || opts.Password == "" {
+			cluster_tools.PrintError("启用%s认证时，必须提供用户名和密码", opts.AuthType)
+			os.Exit(1)
+		}
+	}
+
+	// 诊断模式自己检查每个地址的每个步骤，不需要先建立集群连接
+	if *diagnose {
+		if !advanced_tools.Diagnose(opts) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 逐个检查bootstrap地址，只用端口开放的地址建立连接，单个节点宕机不影响使用
+	opts.Brokers = advanced_tools.CheckPorts(opts.Brokers, opts.Client.Timeout(), messageOutput)
+	if len(opts.Brokers) == 0 {
+		cluster_tools.PrintError("端口未开放或连接失败，请检查Kafka地址和端口是否正确")
+		os.Exit(1)
 	} else {
-		// 配置 Kafka
-		config := sarama.NewConfig()
-		config.Producer.Return.Successes = true
-		// 检查 Kafka broker 连接
-		if !advanced_tools.CheckBrokerConnection(brokers, config) {
-			color.Red("连接kafka地址失败，请检查地址是否正确")
-			return
-		} else {
-			color.Green("✔连接PLAINTEXT认证kafka地址成功")
-			plaintext_ops(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail,
-				topicName, topicKeyword, groupKeyword, groupTopicKeyword, groupName, brokers, config, testConsumeFromBeginning, testConsumeFromLatest)
+		color.Green("✔端口已开放，连接成功")
+	}
+
+	fmt.Fprintln(messageOutput, "正在连接kafka地址:", strings.Join(opts.Brokers, ","))
+
+	// 整个命令周期只创建一次集群连接，所有操作共用
+	cluster, ok := advanced_tools.CheckBrokerConnection(opts)
+	if !ok {
+		cluster_tools.PrintError("连接kafka地址失败，请检查地址、用户名和密码是否正确")
+		os.Exit(1)
+	}
+	defer cluster.Close()
+	if cluster.TLSEnabled() {
+		color.Green("✔连接%s+TLS认证kafka地址成功", cluster.AuthType())
+	} else {
+		color.Green("✔连接%s认证kafka地址成功", cluster.AuthType())
+	}
+
+	cluster_report_ops(cluster, health, brokerList, logDirs, jsonOutput, topicName, topicKeyword, topicRegex, top, lagThreshold)
+	broker_ops(cluster, brokerConfigs, brokerAlterConfig, dynamicOnly, assumeYes, validateOnly, brokerID, configSet, configDelete, configAppend)
+	leader_ops(cluster, leaderReport, electLeaders, assumeYes, topicName, topicKeyword, topicRegex, electionType)
+	reassign_ops(cluster, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, assumeYes,
+		topicName, topicKeyword, topicRegex, targetBrokers, reassignFile, throttle, interval)
+	cluster_ops(cluster, listTopics, topicDetail, topicGroups, listConsumerGroups, consumerGroupsDetail, askTopicGroups,
+		topicName, topicKeyword, groupKeyword, groupTopicKeyword, groupName, groupState, testConsumeFromBeginning, testConsumeFromLatest, watch, timeLag, interval)
+	group_admin_ops(cluster, groupResetOffsets, groupDelete, groupDeleteOffsets, assumeYes, validateOnly,
+		groupName, groupKeyword, groupRegex, topicName, resetStrategy, resetValue, resetPartitions)
+	topic_admin_ops(cluster, topicCreate, topicDelete, topicConfigsDescribe, topicAlterConfig, topicAddPartitions, assumeYes,
+		topicName, topicKeyword, topicRegex, partitions, replicationFactor, sampleSize,
+		topicConfigs, configSet, configDelete, configAppend, validateOnly)
+}
+
+// inputIndex 循环读取输入，直到得到1-max之间的序号
+func inputIndex(label string, max int) int {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s(1-%d):", label, max)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		num, err := strconv.Atoi(input)
+		if err == nil && num > 0 && num <= max {
+			return num
 		}
+		cluster_tools.PrintError("输入无效，请重新输入。")
 	}
 }
 
-func plaintext_ops(listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail *bool,
-	topicName, topicKeyword, groupKeyword, groupTopicKeyword, groupName *string, brokers []string, config *sarama.Config,
-	testConsumeFromBeginning *int, testConsumeFromLatest *bool) {
+func cluster_ops(cluster cluster_tools.Cluster, listTopics, topicDetail, topicGroups, listConsumerGroups, consumerGroupsDetail *bool, askTopicGroups bool,
+	topicName, topicKeyword, groupKeyword, groupTopicKeyword, groupName, groupState *string,
+	testConsumeFromBeginning *int, testConsumeFromLatest, watch, timeLag *bool, interval *int) {
 	if *listTopics {
 		if *topicName != "" {
 			*topicKeyword = *topicName
 		}
-		topic_tools.ShowTopics(brokers, config, *topicKeyword)
+		topic_tools.ShowTopics(cluster, *topicKeyword)
 	}
 
 	if *listConsumerGroups {
 		if *groupName != "" {
 			*groupKeyword = *groupName
 		}
-		groupNames, err := consumer_tools.GetAllConsumerGroups(brokers, config, *groupKeyword)
+		states, err := consumer_tools.ParseGroupStates(*groupState)
 		if err != nil {
-			color.Red("获取消费组失败:", err)
+			cluster_tools.PrintError("%v", err)
 			return
 		}
-		fmt.Println("Kafka消费组列表:")
-		for i, group := range groupNames {
-			fmt.Printf("%d. %s\n", i+1, group)
+		summaries, err := consumer_tools.ListGroupSummaries(cluster, *groupKeyword, states)
+		if err != nil {
+			cluster_tools.PrintError("获取消费组失败: %v", err)
+			return
+		}
+		if len(summaries) == 0 {
+			color.Yellow("没有匹配的消费组")
+			return
 		}
+		fmt.Println("Kafka消费组列表:")
+		consumer_tools.PrintGroupSummaries(summaries)
 	}
 
 	if *topicDetail {
 		if *topicName != "" {
-			topic_tools.TopicDetail(brokers, config, *topicName)
+			topic_tools.TopicDetail(cluster, *topicName)
 		} else {
-			topic_map := topic_tools.ShowTopicsReturnMap(brokers, config, *topicKeyword)
-
-			reader := bufio.NewReader(os.Stdin)
-			var idx int
-
-			for {
-				fmt.Printf("请输入要查看topic对应的id(1-%d):", len(topic_map))
-				input, _ := reader.ReadString('\n')
-				input = strings.TrimSpace(input)
-				num, err := strconv.Atoi(input)
-				if err == nil && num > 0 && num <= len(topic_map) {
-					idx = num
-					break
-				}
-				color.Red("输入无效，请重新输入。")
+			topic_map := topic_tools.ShowTopicsReturnMap(cluster, *topicKeyword)
+			if len(topic_map) == 0 {
+				cluster_tools.PrintError("未找到topic")
+				return
 			}
+
+			idx := inputIndex("请输入要查看topic对应的id", len(topic_map))
 			fmt.Printf("查看第 %d 个 topic: %s\n", idx, topic_map[idx])
 
-			topic_tools.TopicDetail(brokers, config, topic_map[idx])
+			*topicName = topic_map[idx]
+			topic_tools.TopicDetail(cluster, *topicName)
 		}
 
+		if askTopicGroups && advanced_tools.ConfirmYesNo("是否查看消费该topic的消费组") {
+			showTopicGroups(cluster, *topicName)
+		}
+		return
+	}
+
+	if *topicGroups {
+		topic, ok := selectTopic(cluster, *topicName, *topicKeyword)
+		if !ok {
+			return
+		}
+		showTopicGroups(cluster, topic)
 		return
 	}
 
 	if *consumerGroupsDetail {
 		fmt.Println("消费组关键字:", *groupTopicKeyword)
-		var selectedGroupName string
-		if *groupName != "" {
-			selectedGroupName = *groupName
+		selectedGroupName, ok := selectGroup(cluster, *groupName, *groupKeyword, "请输入要查看消费组对应的id")
+		if !ok {
+			return
+		}
+
+		if *watch {
+			if err := consumer_tools.WatchConsumerGroup(cluster, selectedGroupName, *groupTopicKeyword, time.Duration(*interval)*time.Second); err != nil {
+				cluster_tools.PrintError("刷新消费组详情失败: %v", err)
+			}
+			return
+		}
+		if summary, err := consumer_tools.DescribeGroupSummary(cluster, selectedGroupName); err != nil {
+			cluster_tools.PrintError("获取消费组状态失败: %v", err)
 		} else {
-			groupNames, err := consumer_tools.GetAllConsumerGroups(brokers, config, *groupKeyword)
+			consumer_tools.PrintGroupHeader(summary)
+		}
+		if *timeLag {
+			fmt.Printf("正在采样 %d 秒内的消费和生产速率...\n", *interval)
+			lags, err := consumer_tools.GetConsumerGroupTimeLag(cluster, selectedGroupName, *groupTopicKeyword, time.Duration(*interval)*time.Second)
 			if err != nil {
-				color.Red("获取消费组失败:", err)
-				return
-			}
-			fmt.Println("Kafka消费组列表:")
-			for i, group := range groupNames {
-				fmt.Printf("%d. %s\n", i+1, group)
-			}
-
-			reader := bufio.NewReader(os.Stdin)
-			var idx int
-
-			for {
-				fmt.Printf("请输入要查看消费组对应的id(1-%d):", len(groupNames))
-				input, _ := reader.ReadString('\n')
-				input = strings.TrimSpace(input)
-				num, err := strconv.Atoi(input)
-				if err == nil && num > 0 && num <= len(groupNames) {
-					idx = num
-					break
-				}
-				color.Red("输入无效，请重新输入。")
+				cluster_tools.PrintError("获取消费组时间lag失败: %v", err)
+			} else if len(lags) == 0 {
+				color.Yellow("消费组 %s 没有匹配的分区", selectedGroupName)
+			} else {
+				consumer_tools.PrintConsumerGroupTimeLag(selectedGroupName, lags, time.Duration(*interval)*time.Second)
 			}
-			fmt.Printf("查看第 %d 个消费组: %s\n", idx, groupNames[idx-1])
-			selectedGroupName = groupNames[idx-1]
+			return
 		}
 
-		table, err := consumer_tools.GetConsumerGroupDetailsTable(brokers, config, selectedGroupName, *groupTopicKeyword)
-
-		// if table[1][0] == "未找到消费组" {
-		// 	fmt.Println("未找到消费组:", groupNames[idx-1])
-		// 	return
-		// } else if table[1][1] == "0" {
-		// 	fmt.Println("消费组没有成员:", groupNames[idx-1])
-		// 	return
-		// }
-
+		table, err := consumer_tools.GetConsumerGroupDetailsTable(cluster, selectedGroupName, *groupTopicKeyword)
 		if err != nil {
-			color.Red("获取消费组详情失败:", err)
+			cluster_tools.PrintError("获取消费组详情失败: %v", err)
 			return
+		} else if len(table) == 0 {
+			color.Yellow("消费组 %s 没有匹配的分区", selectedGroupName)
 		} else {
 			table_header := []string{"GROUP", "TOPIC", "PARTITION", "CURRENT-OFFSET", "LOG-END-OFFSET", "LAG", "CONSUMER-ID", "HOST", "CLIENT-ID"}
 
@@ -263,217 +607,639 @@ func plaintext_ops(listTopics, topicDetail, listConsumerGroups, consumerGroupsDe
 	}
 
 	if *testConsumeFromBeginning > 0 {
-		topic_map := topic_tools.ShowTopicsReturnMap(brokers, config, *topicKeyword)
-
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
-
-		for {
-			fmt.Printf("请输入要消费的topic对应的id(1-%d):", len(topic_map))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(topic_map) {
-				idx = num
-				break
-			}
-			color.Red("输入无效，请重新输入。")
+		topic_map := topic_tools.ShowTopicsReturnMap(cluster, *topicKeyword)
+		if len(topic_map) == 0 {
+			cluster_tools.PrintError("未找到topic")
+			return
 		}
+
+		idx := inputIndex("请输入要消费的topic对应的id", len(topic_map))
 		fmt.Printf("从 %s topic 开始消费 %d 条消息\n", topic_map[idx], *testConsumeFromBeginning)
 
-		err := consumer_tools.ConsumeFromBeginning(brokers, config, topic_map[idx], *testConsumeFromBeginning)
+		err := consumer_tools.ConsumeFromBeginning(cluster, topic_map[idx], *testConsumeFromBeginning)
 		if err != nil {
-			color.Red("消费失败:", err)
+			cluster_tools.PrintError("消费失败: %v", err)
 		}
 		return
 	}
 
 	if *testConsumeFromLatest {
-		topic_map := topic_tools.ShowTopicsReturnMap(brokers, config, *topicKeyword)
-
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
-
-		for {
-			fmt.Printf("请输入要消费的topic对应的id(1-%d):", len(topic_map))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(topic_map) {
-				idx = num
-				break
-			}
-			color.Red("输入无效，请重新输入。")
+		topic_map := topic_tools.ShowTopicsReturnMap(cluster, *topicKeyword)
+		if len(topic_map) == 0 {
+			cluster_tools.PrintError("未找到topic")
+			return
 		}
-		// fmt.Println(*testConsumeFromLatest)
+
+		idx := inputIndex("请输入要消费的topic对应的id", len(topic_map))
 		fmt.Printf("从 %s topic 开始消费最新消息\n", topic_map[idx])
 
-		err := consumer_tools.ConsumeFromLastest(brokers, config, topic_map[idx])
+		err := consumer_tools.ConsumeFromLastest(cluster, topic_map[idx])
 		if err != nil {
-			color.Red("消费失败:", err)
+			cluster_tools.PrintError("消费失败: %v", err)
 		}
 		return
 	}
 }
 
-func sha_ops(brokers []string, username, password string,
-	listTopics, topicDetail, listConsumerGroups, consumerGroupsDetail *bool,
-	topicKeyword, groupKeyword *string, testConsumeFromBeginning *int, testConsumeFromLatest *bool, ssl_type *string) {
-	if *listTopics {
-		if *ssl_type == "SASL/SCRAM-SHA-256" {
-			topic_tools.ShowTopicsSHA(brokers[0], username, password, "SASL/SCRAM-SHA-256", *topicKeyword)
-		} else if *ssl_type == "SASL/SCRAM-SHA-512" {
-			topic_tools.ShowTopicsSHA(brokers[0], username, password, "SASL/SCRAM-SHA-512", *topicKeyword)
-		} else {
-			color.Red("不支持的SSL类型:", *ssl_type)
+// cluster_report_ops 集群级别的只读报告
+func cluster_report_ops(cluster cluster_tools.Cluster, health, brokerList, logDirs, jsonOutput *bool,
+	topicName, topicKeyword, topicRegex *string, top *int, lagThreshold *int64) {
+	if *logDirs {
+		// 指定了topic范围时只统计这些topic，并显示每个分区副本
+		topics, ok := scopeTopics(cluster, *topicName, *topicKeyword, *topicRegex)
+		if !ok {
 			return
 		}
+		if err := broker_tools.ShowLogDirs(cluster, topics, *top, *lagThreshold, len(topics) > 0); err != nil {
+			cluster_tools.PrintError("获取日志目录失败: %v", err)
+		}
+		return
 	}
-
-	if *topicDetail {
-		topic_map := topic_tools.ShowTopicsSHAReturnMap(brokers[0], username, password, *ssl_type, *topicKeyword)
-
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
-
-		for {
-			fmt.Printf("请输入要查看topic对应的id(1-%d):", len(topic_map))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(topic_map) {
-				idx = num
-				break
+	if *brokerList {
+		brokers, err := broker_tools.DescribeBrokers(cluster)
+		if err != nil {
+			cluster_tools.PrintError("获取broker列表失败: %v", err)
+			return
+		}
+		if *jsonOutput {
+			if err := broker_tools.PrintBrokersJSON(os.Stdout, brokers); err != nil {
+				cluster_tools.PrintError("%v", err)
 			}
-			color.Red("输入无效，请重新输入。")
+			return
+		}
+		broker_tools.PrintBrokers(brokers)
+		return
+	}
+	if *health {
+		problems, err := topic_tools.ClusterHealth(cluster)
+		if err != nil {
+			cluster_tools.PrintError("健康检查失败: %v", err)
+			cluster.Close()
+			os.Exit(1)
+		}
+		if problems > 0 {
+			cluster.Close()
+			os.Exit(2)
 		}
-		fmt.Printf("查看第 %d 个 topic: %s\n", idx, topic_map[idx])
+		return
+	}
+}
 
-		topic_tools.TopicDetailSHA(brokers[0], username, password, *ssl_type, topic_map[idx])
+// broker_ops 查看和修改broker配置
+func broker_ops(cluster cluster_tools.Cluster, brokerConfigs, brokerAlterConfig, dynamicOnly, assumeYes, validateOnly *bool,
+	brokerID, configSet, configDelete, configAppend *string) {
+	if !*brokerConfigs && !*brokerAlterConfig {
+		return
+	}
+	if *brokerID == "" {
+		cluster_tools.PrintError("必须通过-broker-id指定broker ID，或使用-broker-id %s 指定集群默认配置", broker_tools.ClusterDefault)
 		return
 	}
 
-	if *listConsumerGroups {
-		groupNames, err := consumer_tools.GetAllConsumerGroupsSHA(brokers[0], username, password, *ssl_type, *groupKeyword)
-		if err != nil {
-			color.Red("未找到消费组")
-			// fmt.Println("获取消费组失败:", err)
+	if *brokerConfigs {
+		if err := broker_tools.ShowBrokerConfigs(cluster, *brokerID, *dynamicOnly); err != nil {
+			cluster_tools.PrintError("获取broker配置失败: %v", err)
+		}
+		return
+	}
+
+	set, err := format_tools.ParseKeyValues(*configSet)
+	if err != nil {
+		cluster_tools.PrintError("%v", err)
+		return
+	}
+	appendValues, err := format_tools.ParseKeyValues(*configAppend)
+	if err != nil {
+		cluster_tools.PrintError("%v", err)
+		return
+	}
+	deleteKeys := strings.Split(*configDelete, ",")
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_3_0_0, "修改broker配置"); err != nil {
+		cluster_tools.PrintError("%v", err)
+		return
+	}
+	if err := broker_tools.PreviewBrokerConfigChanges(cluster, *brokerID, set, deleteKeys, appendValues); err != nil {
+		cluster_tools.PrintError("%v", err)
+		return
+	}
+	if !*assumeYes && !*validateOnly {
+		if !advanced_tools.ConfirmTyped("即将修改以上broker配置", "yes") {
+			color.Yellow("输入不匹配，已取消修改")
 			return
 		}
-		// fmt.Println(groupNames)
-		fmt.Println("Kafka消费组列表:")
-		for i, group := range groupNames {
-			fmt.Printf("%d. %s\n", i+1, group)
+	}
+	if err := broker_tools.AlterBrokerConfigs(cluster, *brokerID, set, deleteKeys, appendValues, *validateOnly); err != nil {
+		cluster_tools.PrintError("修改broker配置失败: %v", err)
+	}
+}
+
+// leader_ops leader均衡报告和leader选举
+func leader_ops(cluster cluster_tools.Cluster, leaderReport, electLeaders, assumeYes *bool, topicName, topicKeyword, topicRegex, electionType *string) {
+	if !*leaderReport && !*electLeaders {
+		return
+	}
+	topics, ok := scopeTopics(cluster, *topicName, *topicKeyword, *topicRegex)
+	if !ok {
+		return
+	}
+
+	if *leaderReport {
+		if _, err := topic_tools.LeaderBalanceReport(cluster, topics); err != nil {
+			cluster_tools.PrintError("获取leader分布失败: %v", err)
 		}
+		return
 	}
 
-	if *consumerGroupsDetail {
-		groupNames, err := consumer_tools.GetAllConsumerGroupsSHA(brokers[0], username, password, *ssl_type, *groupKeyword)
+	var election sarama.ElectionType
+	switch strings.ToLower(*electionType) {
+	case "preferred":
+		election = sarama.PreferredElection
+	case "unclean":
+		election = sarama.UncleanElection
+	default:
+		cluster_tools.PrintError("参数错误：-election-type 只能是 preferred 或 unclean")
+		return
+	}
+	candidates, err := topic_tools.LeaderElectionCandidates(cluster, topics, election)
+	if err != nil {
+		cluster_tools.PrintError("获取分区元数据失败: %v", err)
+		return
+	}
+	if len(candidates) == 0 {
+		if election == sarama.UncleanElection {
+			color.Green("✔没有缺少leader的分区，不需要unclean选举")
+		} else {
+			color.Green("✔所有分区的leader都是优先副本，不需要选举")
+		}
+		return
+	}
+	fmt.Printf("将对 %d 个分区触发%s选举\n", len(candidates), *electionType)
+	if election == sarama.UncleanElection {
+		cluster_tools.PrintError("unclean选举会让不在ISR中的副本成为leader，可能丢失已提交的消息")
+		if !*assumeYes && !advanced_tools.ConfirmTyped("即将执行unclean选举", "unclean") {
+			color.Yellow("已取消")
+			return
+		}
+	}
+	failed, err := topic_tools.ElectLeaders(cluster, election, candidates)
+	if err != nil {
+		cluster_tools.PrintError("leader选举失败: %v", err)
+		return
+	}
+	if failed > 0 {
+		cluster_tools.PrintError("%d 个分区选举失败", failed)
+		return
+	}
+	color.Green("✔%d 个分区选举完成", len(candidates))
+}
 
+// reassign_ops 分区重分配：生成计划、执行、跟踪进度和取消
+func reassign_ops(cluster cluster_tools.Cluster, reassignGenerate, reassignExecute, reassignStatus, reassignCancel, assumeYes *bool,
+	topicName, topicKeyword, topicRegex, targetBrokers, reassignFile *string, throttle, interval *int) {
+	if *reassignGenerate {
+		brokers, err := topic_tools.ParseBrokerIDs(*targetBrokers)
 		if err != nil {
-			color.Red("未找到消费组")
-			// fmt.Println("获取消费组失败:", err)
+			cluster_tools.PrintError("%v", err)
 			return
 		}
-		fmt.Println("Kafka消费组列表:")
-		for i, group := range groupNames {
-			fmt.Printf("%d. %s\n", i+1, group)
+		topics, err := topic_tools.MatchTopics(cluster, *topicName, *topicKeyword, *topicRegex)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
 		}
+		if len(topics) == 0 {
+			color.Yellow("没有匹配的topic")
+			return
+		}
+		target, current, err := topic_tools.GenerateReassignment(cluster, topics, brokers)
+		if err != nil {
+			cluster_tools.PrintError("生成重分配计划失败: %v", err)
+			return
+		}
+		if err := topic_tools.SaveReassignmentPlan(*reassignFile, target); err != nil {
+			cluster_tools.PrintError("保存重分配计划失败: %v", err)
+			return
+		}
+		rollbackFile := topic_tools.RollbackPlanPath(*reassignFile)
+		if err := topic_tools.SaveReassignmentPlan(rollbackFile, current); err != nil {
+			cluster_tools.PrintError("保存回滚文件失败: %v", err)
+			return
+		}
+		moves, err := topic_tools.PlanMoves(cluster, target)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		if len(moves) > 0 {
+			topic_tools.PrintReassignmentMoves(moves)
+		}
+		color.Green("✔已生成 %d 个topic的重分配计划，其中 %d 个分区需要变更: %s", len(topics), len(moves), *reassignFile)
+		fmt.Println("当前分配已保存到:", rollbackFile)
+		return
+	}
 
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
+	if *reassignExecute {
+		if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "分区重分配"); err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		plan, err := topic_tools.LoadReassignmentPlan(*reassignFile)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		moves, err := topic_tools.PlanMoves(cluster, plan)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		if len(moves) == 0 {
+			color.Green("✔所有分区的副本已经与计划一致，不需要重分配")
+			return
+		}
+		topic_tools.PrintReassignmentMoves(moves)
+		if *throttle <= 0 {
+			color.Yellow("未设置-throttle，副本同步会占用尽可能多的带宽")
+		}
+		if !*assumeYes && !advanced_tools.ConfirmTyped(fmt.Sprintf("即将重分配 %d 个分区", len(moves)), "yes") {
+			color.Yellow("已取消")
+			return
+		}
+		if err := topic_tools.ExecuteReassignment(cluster, plan, moves, int64(*throttle)); err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		if err := topic_tools.WaitReassignment(cluster, plan, time.Duration(*interval)*time.Second); err != nil {
+			cluster_tools.PrintError("%v", err)
+		}
+		return
+	}
 
-		for {
-			fmt.Printf("请输入要查看消费组对应的id(1-%d):", len(groupNames))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(groupNames) {
-				idx = num
-				break
+	if *reassignStatus || *reassignCancel {
+		// 计划文件存在时只处理计划中的分区，否则处理所有topic
+		var plan topic_tools.ReassignmentPlan
+		if _, err := os.Stat(*reassignFile); err == nil {
+			if plan, err = topic_tools.LoadReassignmentPlan(*reassignFile); err != nil {
+				cluster_tools.PrintError("%v", err)
+				return
 			}
-			color.Red("输入无效，请重新输入。")
-			// fmt.Println("输入无效，请重新输入。")
+			fmt.Println("使用重分配计划:", *reassignFile)
+		}
+		if *reassignStatus && len(plan.Partitions) > 0 {
+			if err := topic_tools.WaitReassignment(cluster, plan, time.Duration(*interval)*time.Second); err != nil {
+				cluster_tools.PrintError("%v", err)
+			}
+			return
 		}
-		fmt.Printf("查看第 %d 个消费组: %s\n", idx, groupNames[idx-1])
-
-		table, err := consumer_tools.GetConsumerGroupDetailsTableSHA(brokers[0], username, password, *ssl_type, groupNames[idx-1])
 
-		if table[1][0] == "未找到消费组" {
-			color.Red("未找到消费组:", groupNames[idx-1])
-			// fmt.Println("未找到消费组:", groupNames[idx-1])
+		status, err := topic_tools.ListReassignments(cluster, plan.Topics())
+		if err != nil {
+			cluster_tools.PrintError("查询正在进行的重分配失败: %v", err)
 			return
-		} else if table[1][1] == "0" {
-			color.Red("消费组没有成员:", groupNames[idx-1])
-			// fmt.Println("消费组没有成员:", groupNames[idx-1])
+		}
+		if len(plan.Partitions) > 0 {
+			status = plan.FilterStatus(status)
+		}
+		if len(status) == 0 {
+			color.Green("✔没有正在进行的重分配")
 			return
 		}
+		topic_tools.PrintReassignmentStatus(status)
+		if *reassignStatus {
+			return
+		}
+		if !*assumeYes && !advanced_tools.ConfirmTyped("即将取消以上分区的重分配", "yes") {
+			color.Yellow("已取消")
+			return
+		}
+		if err := topic_tools.CancelReassignment(cluster, status); err != nil {
+			cluster_tools.PrintError("取消重分配失败: %v", err)
+		}
+		return
+	}
+}
 
+// group_admin_ops 重置offset、删除消费组等会改变消费组状态的操作
+func group_admin_ops(cluster cluster_tools.Cluster, groupResetOffsets, groupDelete, groupDeleteOffsets, assumeYes, validateOnly *bool,
+	groupName, groupKeyword, groupRegex, topicName, resetStrategy, resetValue, resetPartitions *string) {
+	if *groupDelete {
+		groups, err := consumer_tools.MatchGroups(cluster, *groupName, *groupKeyword, *groupRegex)
 		if err != nil {
-			fmt.Println("获取消费组详情失败:", err)
+			cluster_tools.PrintError("匹配消费组失败: %v", err)
 			return
-		} else {
-			table_header := []string{"GROUP", "TOPIC", "PARTITION", "CURRENT-OFFSET", "LOG-END-OFFSET", "LAG", "CONSUMER-ID", "HOST", "CLIENT-ID"}
+		}
+		if len(groups) == 0 {
+			color.Yellow("没有匹配的消费组")
+			return
+		}
 
-			format_tools.PrintPrettyTable(table_header, table)
+		fmt.Println("即将删除以下消费组:")
+		consumer_tools.PrintGroupsPreview(cluster, groups)
+		if !*assumeYes {
+			expected := "yes"
+			if len(groups) == 1 {
+				expected = groups[0]
+			}
+			if !advanced_tools.ConfirmTyped(fmt.Sprintf("即将删除以上 %d 个消费组及其offset，该操作不可恢复", len(groups)), expected) {
+				color.Yellow("输入不匹配，已取消删除")
+				return
+			}
+		}
+		if failed := consumer_tools.DeleteGroups(cluster, groups); failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *groupDeleteOffsets {
+		if *topicName == "" {
+			cluster_tools.PrintError("删除消费组offset时必须通过-topic-name指定topic")
+			return
+		}
+		if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "删除消费组offset"); err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		group, ok := selectGroup(cluster, *groupName, *groupKeyword, "请输入要删除offset的消费组对应的id")
+		if !ok {
+			return
+		}
+		partitions, err := consumer_tools.GroupTopicOffsets(cluster, group, *topicName)
+		if err != nil {
+			cluster_tools.PrintError("获取消费组offset失败: %v", err)
+			return
+		}
+		if len(partitions) == 0 {
+			color.Yellow("消费组 %s 在topic %s 上没有已提交的offset", group, *topicName)
+			return
+		}
+		if !*assumeYes {
+			message := fmt.Sprintf("即将删除消费组 %s 在topic %s 上 %d 个分区的offset", group, *topicName, len(partitions))
+			if !advanced_tools.ConfirmTyped(message, *topicName) {
+				color.Yellow("输入不匹配，已取消删除")
+				return
+			}
+		}
+		failed, err := consumer_tools.DeleteGroupTopicOffsets(cluster, group, *topicName, partitions)
+		if err != nil {
+			cluster_tools.PrintError("删除offset失败: %v", err)
+			return
+		}
+		if failed > 0 {
+			os.Exit(1)
 		}
+		color.Green("✔已删除消费组 %s 在topic %s 上的offset", group, *topicName)
+		return
+	}
 
+	if *groupResetOffsets {
+		if *resetStrategy == "" {
+			cluster_tools.PrintError("重置offset时必须通过-reset-strategy指定策略")
+			return
+		}
+		partitions, err := consumer_tools.ParsePartitions(*resetPartitions)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		if len(partitions) > 0 && *topicName == "" {
+			cluster_tools.PrintError("-reset-partitions 必须与 -topic-name 一起使用")
+			return
+		}
+		group, ok := selectGroup(cluster, *groupName, *groupKeyword, "请输入要重置offset的消费组对应的id")
+		if !ok {
+			return
+		}
+		if err := consumer_tools.CheckGroupInactive(cluster, group); err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		resets, err := consumer_tools.PlanOffsetReset(cluster, group, *topicName, partitions, *resetStrategy, *resetValue)
+		if err != nil {
+			cluster_tools.PrintError("计算重置后的offset失败: %v", err)
+			return
+		}
+		consumer_tools.PrintOffsetResetPreview(group, resets)
+		if *validateOnly {
+			color.Yellow("validate-only模式，未实际重置offset")
+			return
+		}
+		if !*assumeYes && !advanced_tools.ConfirmTyped(fmt.Sprintf("即将重置消费组 %s 的 %d 个分区的offset", group, len(resets)), "yes") {
+			color.Yellow("输入不匹配，已取消重置")
+			return
+		}
+		failed, err := consumer_tools.ResetGroupOffsets(cluster, group, resets)
+		if err != nil {
+			cluster_tools.PrintError("重置offset失败: %v", err)
+			return
+		}
+		if failed > 0 {
+			cluster_tools.PrintError("%d 个分区的offset重置失败", failed)
+			return
+		}
+		color.Green("✔已重置消费组 %s 的 %d 个分区的offset", group, len(resets))
 		return
 	}
+}
 
-	if *testConsumeFromBeginning > 0 { // 从头消费消息
-		topic_map := topic_tools.ShowTopicsSHAReturnMap(brokers[0], username, password, *ssl_type, *topicKeyword)
+// topic_admin_ops 创建、删除、修改topic等会改变集群状态的操作
+func topic_admin_ops(cluster cluster_tools.Cluster, topicCreate, topicDelete, topicConfigsDescribe, topicAlterConfig, topicAddPartitions, assumeYes *bool,
+	topicName, topicKeyword, topicRegex *string, partitions, replicationFactor, sampleSize *int,
+	topicConfigs, configSet, configDelete, configAppend *string, validateOnly *bool) {
+	if *topicCreate {
+		if *topicName == "" {
+			cluster_tools.PrintError("创建topic时必须通过-topic-name指定名称")
+			return
+		}
+		configs, err := format_tools.ParseKeyValues(*topicConfigs)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		err = topic_tools.CreateTopic(cluster, *topicName, int32(*partitions), int16(*replicationFactor), configs, *validateOnly)
+		if err != nil {
+			cluster_tools.PrintError("创建topic失败: %v", err)
+		}
+		return
+	}
 
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
+	if *topicDelete {
+		topics, err := topic_tools.MatchTopics(cluster, *topicName, *topicKeyword, *topicRegex)
+		if err != nil {
+			cluster_tools.PrintError("匹配topic失败: %v", err)
+			return
+		}
+		if len(topics) == 0 {
+			color.Yellow("没有匹配的topic")
+			return
+		}
 
-		for {
-			fmt.Printf("请输入要消费的topic对应的id(1-%d):", len(topic_map))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(topic_map) {
-				idx = num
-				break
+		fmt.Println("即将删除以下topic:")
+		topic_tools.PrintTopicsPreview(cluster, topics)
+		if !*assumeYes {
+			expected := "yes"
+			if len(topics) == 1 {
+				expected = topics[0]
+			}
+			if !advanced_tools.ConfirmTyped(fmt.Sprintf("即将删除以上 %d 个topic，该操作不可恢复", len(topics)), expected) {
+				color.Yellow("输入不匹配，已取消删除")
+				return
 			}
-			color.Red("输入无效，请重新输入。")
-			// fmt.Println("输入无效，请重新输入。")
 		}
-		fmt.Printf("从 %s topic 开始消费 %d 条消息\n", topic_map[idx], *testConsumeFromBeginning)
+		if failed := topic_tools.DeleteTopics(cluster, topics); failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
-		err := consumer_tools.ConsumeFromBeginningSHA(brokers[0], username, password, *ssl_type, topic_map[idx], *testConsumeFromBeginning)
+	if *topicConfigsDescribe {
+		topic, ok := selectTopic(cluster, *topicName, *topicKeyword)
+		if !ok {
+			return
+		}
+		if err := topic_tools.ShowTopicConfigs(cluster, topic); err != nil {
+			cluster_tools.PrintError("获取topic配置失败: %v", err)
+		}
+		return
+	}
+
+	if *topicAlterConfig {
+		set, err := format_tools.ParseKeyValues(*configSet)
+		if err != nil {
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		appendValues, err := format_tools.ParseKeyValues(*configAppend)
 		if err != nil {
-			color.Red("消费失败:", err)
-			// fmt.Println("消费失败:", err)
+			cluster_tools.PrintError("%v", err)
+			return
+		}
+		topic, ok := selectTopic(cluster, *topicName, *topicKeyword)
+		if !ok {
+			return
+		}
+		err = topic_tools.AlterTopicConfigs(cluster, topic, set, strings.Split(*configDelete, ","), appendValues, *validateOnly)
+		if err != nil {
+			cluster_tools.PrintError("修改topic配置失败: %v", err)
 		}
 		return
 	}
 
-	if *testConsumeFromLatest {
-		topic_map := topic_tools.ShowTopicsSHAReturnMap(brokers[0], username, password, *ssl_type, *topicKeyword)
-
-		reader := bufio.NewReader(os.Stdin)
-		var idx int
-
-		for {
-			fmt.Printf("请输入要消费的topic对应的id(1-%d):", len(topic_map))
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
-			num, err := strconv.Atoi(input)
-			if err == nil && num > 0 && num <= len(topic_map) {
-				idx = num
-				break
+	if *topicAddPartitions {
+		if *partitions <= 0 {
+			cluster_tools.PrintError("增加分区时必须通过-partitions指定扩容后的分区总数")
+			return
+		}
+		topic, ok := selectTopic(cluster, *topicName, *topicKeyword)
+		if !ok {
+			return
+		}
+		// 先采样key分析按key有序的影响，确认后再真正增加分区
+		if _, err := topic_tools.PreviewAddPartitions(cluster, topic, int32(*partitions), *sampleSize); err != nil {
+			cluster_tools.PrintError("分析增加分区的影响失败: %v", err)
+			return
+		}
+		if !*assumeYes && !*validateOnly {
+			if !advanced_tools.ConfirmTyped(fmt.Sprintf("即将把topic %s 的分区数增加到 %d，分区增加后无法减少", topic, *partitions), "yes") {
+				color.Yellow("输入不匹配，已取消增加分区")
+				return
 			}
-			color.Red("输入无效，请重新输入。")
-			// fmt.Println("输入无效，请重新输入。")
 		}
-		fmt.Printf("从 %s topic 开始消费最新消息\n", topic_map[idx])
-
-		err := consumer_tools.ConsumeFromLatestSHA(brokers[0], username, password, *ssl_type, topic_map[idx])
-		if err != nil {
-			color.Red("消费失败:", err)
-			// fmt.Println("消费失败:", err)
+		if err := topic_tools.AddPartitions(cluster, topic, int32(*partitions), *validateOnly); err != nil {
+			cluster_tools.PrintError("增加分区失败: %v", err)
 		}
 		return
 	}
 }
+
+// selectGroup 指定了消费组名称时直接返回，否则列出匹配关键词的消费组供选择
+func selectGroup(cluster cluster_tools.Cluster, groupName, groupKeyword, label string) (string, bool) {
+	if groupName != "" {
+		return groupName, true
+	}
+	groupNames, err := consumer_tools.GetAllConsumerGroups(cluster, groupKeyword)
+	if err != nil {
+		cluster_tools.PrintError("获取消费组失败: %v", err)
+		return "", false
+	}
+	if len(groupNames) == 0 {
+		cluster_tools.PrintError("未找到消费组")
+		return "", false
+	}
+	fmt.Println("Kafka消费组列表:")
+	for i, group := range groupNames {
+		fmt.Printf("%d. %s\n", i+1, group)
+	}
+
+	idx := inputIndex(label, len(groupNames))
+	fmt.Printf("选择第 %d 个消费组: %s\n", idx, groupNames[idx-1])
+	return groupNames[idx-1], true
+}
+
+// scopeTopics 按名称、关键词或正则限定topic范围，都没有指定时返回nil表示所有topic
+func scopeTopics(cluster cluster_tools.Cluster, topicName, topicKeyword, topicRegex string) ([]string, bool) {
+	if topicName == "" && topicKeyword == "" && topicRegex == "" {
+		return nil, true
+	}
+	topics, err := topic_tools.MatchTopics(cluster, topicName, topicKeyword, topicRegex)
+	if err != nil {
+		cluster_tools.PrintError("%v", err)
+		return nil, false
+	}
+	if len(topics) == 0 {
+		color.Yellow("没有匹配的topic")
+		return nil, false
+	}
+	return topics, true
+}
+
+// selectTopic 指定了topic名称时直接返回，否则列出匹配关键词的topic供选择
+func selectTopic(cluster cluster_tools.Cluster, topicName, topicKeyword string) (string, bool) {
+	if topicName != "" {
+		return topicName, true
+	}
+	topic_map := topic_tools.ShowTopicsReturnMap(cluster, topicKeyword)
+	if len(topic_map) == 0 {
+		cluster_tools.PrintError("未找到topic")
+		return "", false
+	}
+	idx := inputIndex("请输入要操作的topic对应的id", len(topic_map))
+	fmt.Printf("选择第 %d 个 topic: %s\n", idx, topic_map[idx])
+	return topic_map[idx], true
+}
+
+// showTopicGroups 打印在topic上提交过offset的消费组
+func showTopicGroups(cluster cluster_tools.Cluster, topic string) {
+	groups, err := consumer_tools.TopicConsumerGroups(cluster, topic)
+	if err != nil {
+		cluster_tools.PrintError("获取消费topic %s 的消费组失败: %v", topic, err)
+		return
+	}
+	consumer_tools.PrintTopicConsumerGroups(topic, groups)
+}
+
+// printProfiles 打印配置文件中的集群，当前默认集群用*标记
+func printProfiles(profileConfig *cluster_tools.ProfileConfig) {
+	names := profileConfig.Names()
+	if len(names) == 0 {
+		color.Yellow("配置文件中没有集群配置，可使用-save-profile保存")
+		return
+	}
+	var rows [][]string
+	for _, name := range names {
+		p := profileConfig.Profiles[name]
+		current := ""
+		if name == profileConfig.CurrentContext {
+			current = "*"
+		}
+		authType := p.AuthType
+		if authType == "" {
+			authType = cluster_tools.AuthPlaintext
+		}
+		if p.TLS.Active() {
+			authType += "+TLS"
+		}
+		rows = append(rows, []string{current, name, strings.Join(p.Brokers, ","), authType, p.Username})
+	}
+	format_tools.PrintPrettyTable([]string{"CURRENT", "NAME", "BROKERS", "AUTH", "USER"}, rows)
+}
@@ -0,0 +1,42 @@
+package topic_tools
+
+import "testing"
+
+// 期望值来自Kafka UtilsTest.testMurmur2
+func TestMurmur2(t *testing.T) {
+	cases := []struct {
+		key  string
+		want int32
+	}{
+		{"21", -973932308},
+		{"foobar", -790332482},
+		{"a-little-bit-long-string", -985981536},
+		{"a-little-bit-longer-string", -1486304829},
+		{"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", -58897971},
+		{"abc", 479470107},
+	}
+	for _, c := range cases {
+		if got := murmur2([]byte(c.key)); got != c.want {
+			t.Errorf("murmur2(%q) = %d, want %d", c.key, got, c.want)
+		}
+	}
+}
+
+// 负数哈希值需要先按toPositive去掉符号位再取模，不能直接对int32取模
+func TestDefaultPartition(t *testing.T) {
+	cases := []struct {
+		key           string
+		numPartitions int32
+		want          int32
+	}{
+		{"foobar", 10, 6},
+		{"a-little-bit-long-string", 12, 8},
+		{"abc", 10, 7},
+		{"21", 3, 0},
+	}
+	for _, c := range cases {
+		if got := DefaultPartition([]byte(c.key), c.numPartitions); got != c.want {
+			t.Errorf("DefaultPartition(%q, %d) = %d, want %d", c.key, c.numPartitions, got, c.want)
+		}
+	}
+}
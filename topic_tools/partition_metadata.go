@@ -0,0 +1,93 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"sort"
+	"strings"
+)
+
+// PartitionInfo 分区的leader、副本、ISR和离线副本
+type PartitionInfo struct {
+	Topic     string
+	Partition int32
+	// Leader 为-1表示分区当前没有leader
+	Leader   int32
+	Replicas []int32
+	ISR      []int32
+	Offline  []int32
+}
+
+// UnderReplicated ISR数量少于副本数量
+func (p PartitionInfo) UnderReplicated() bool {
+	return len(p.ISR) < len(p.Replicas)
+}
+
+// Leaderless 分区没有leader，无法读写
+func (p PartitionInfo) Leaderless() bool {
+	return p.Leader < 0
+}
+
+// PreferredLeader 副本列表中的第一个broker为优先leader
+func (p PartitionInfo) PreferredLeader() int32 {
+	if len(p.Replicas) == 0 {
+		return -1
+	}
+	return p.Replicas[0]
+}
+
+// OutOfSync 在副本列表中但不在ISR中的broker
+func (p PartitionInfo) OutOfSync() []int32 {
+	inSync := make(map[int32]bool, len(p.ISR))
+	for _, id := range p.ISR {
+		inSync[id] = true
+	}
+	var out []int32
+	for _, id := range p.Replicas {
+		if !inSync[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// DescribePartitions 从controller获取topic的分区元数据，topics为空时返回所有topic，结果按topic和分区排序
+func DescribePartitions(cluster cluster_tools.Cluster, topics []string) ([]PartitionInfo, error) {
+	metadata, err := cluster.Admin().DescribeTopics(topics)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []PartitionInfo
+	for _, topic := range metadata {
+		if topic.Err != 0 {
+			return nil, fmt.Errorf("获取topic %s 的元数据失败: %v", topic.Name, topic.Err)
+		}
+		for _, p := range topic.Partitions {
+			infos = append(infos, PartitionInfo{
+				Topic:     topic.Name,
+				Partition: p.ID,
+				Leader:    p.Leader,
+				Replicas:  p.Replicas,
+				ISR:       p.Isr,
+				Offline:   p.OfflineReplicas,
+			})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Topic != infos[j].Topic {
+			return infos[i].Topic < infos[j].Topic
+		}
+		return infos[i].Partition < infos[j].Partition
+	})
+	return infos, nil
+}
+
+// formatBrokerIDs 把broker ID列表格式化为"1,2,3"
+func formatBrokerIDs(ids []int32) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%d", id)
+	}
+	return strings.Join(parts, ",")
+}
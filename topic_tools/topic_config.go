@@ -0,0 +1,96 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"sort"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// DescribeTopicConfigs 获取topic的全部配置，包括默认值
+func DescribeTopicConfigs(cluster cluster_tools.Cluster, topic string) ([]sarama.ConfigEntry, error) {
+	entries, err := cluster.Admin().DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: topic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// ShowTopicConfigs 打印topic配置的值和来源(默认值、静态配置或动态覆盖)
+func ShowTopicConfigs(cluster cluster_tools.Cluster, topic string) error {
+	entries, err := DescribeTopicConfigs(cluster, topic)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Topic名称: %s\n", topic)
+	var rows [][]string
+	overrides := 0
+	for _, entry := range entries {
+		if entry.Source == sarama.SourceTopic {
+			overrides++
+		}
+		readOnly := ""
+		if entry.ReadOnly {
+			readOnly = "true"
+		}
+		rows = append(rows, []string{entry.Name, cluster_tools.ConfigValue(entry), cluster_tools.ConfigSourceName(entry.Source), readOnly})
+	}
+	format_tools.PrintPrettyTable([]string{"NAME", "VALUE", "SOURCE", "READ-ONLY"}, rows)
+	fmt.Printf("共 %d 项配置，其中 %d 项为topic级别的动态覆盖\n", len(entries), overrides)
+	return nil
+}
+
+// AlterTopicConfigs 通过IncrementalAlterConfigs修改topic配置，打印修改前后的差异
+func AlterTopicConfigs(cluster cluster_tools.Cluster, topic string, set map[string]string, deleteKeys []string,
+	appendValues map[string]string, validateOnly bool) error {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_3_0_0, "修改topic配置"); err != nil {
+		return err
+	}
+	entries := cluster_tools.IncrementalConfigEntries(set, deleteKeys, appendValues)
+	if len(entries) == 0 {
+		return fmt.Errorf("没有需要修改的配置项")
+	}
+
+	before, err := DescribeTopicConfigs(cluster, topic)
+	if err != nil {
+		return err
+	}
+
+	if err := cluster.Admin().IncrementalAlterConfig(sarama.TopicResource, topic, entries, validateOnly); err != nil {
+		return err
+	}
+	if validateOnly {
+		color.Green("✔校验通过，topic %s 的配置可以修改(validate-only模式，未实际修改)", topic)
+		PrintConfigDiff(cluster_tools.PlannedConfigChanges(before, set, deleteKeys, appendValues, sarama.SourceTopic))
+		return nil
+	}
+
+	after, err := DescribeTopicConfigs(cluster, topic)
+	if err != nil {
+		return err
+	}
+	color.Green("✔修改topic %s 的配置成功", topic)
+	PrintConfigDiff(cluster_tools.DiffConfigs(before, after))
+	return nil
+}
+
+// PrintConfigDiff 打印配置修改前后的差异
+func PrintConfigDiff(changes []cluster_tools.ConfigChange) {
+	if len(changes) == 0 {
+		fmt.Println("配置没有变化")
+		return
+	}
+	var rows [][]string
+	for _, c := range changes {
+		rows = append(rows, []string{c.Name, c.Before, c.BeforeSource, c.After, c.AfterSource})
+	}
+	format_tools.PrintPrettyTable([]string{"NAME", "BEFORE", "BEFORE-SOURCE", "AFTER", "AFTER-SOURCE"}, rows)
+}
@@ -0,0 +1,64 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"sort"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// CreateTopic 创建topic，partitions和replicationFactor为-1时使用broker的num.partitions和default.replication.factor，
+// validateOnly为true时只让broker校验参数而不实际创建
+func CreateTopic(cluster cluster_tools.Cluster, topic string, partitions int32, replicationFactor int16,
+	configs map[string]string, validateOnly bool) error {
+	// 使用broker默认值需要CreateTopics v4(Kafka 2.4)，低版本协议会被broker拒绝
+	if partitions < 0 || replicationFactor < 0 {
+		if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "使用broker默认的分区数和副本数"); err != nil {
+			return fmt.Errorf("%v，或通过-partitions和-replication-factor显式指定", err)
+		}
+	}
+	detail := &sarama.TopicDetail{
+		NumPartitions:     partitions,
+		ReplicationFactor: replicationFactor,
+		ConfigEntries:     make(map[string]*string, len(configs)),
+	}
+	for key, value := range configs {
+		v := value
+		detail.ConfigEntries[key] = &v
+	}
+
+	fmt.Printf("Topic名称: %s\n", topic)
+	fmt.Printf("Partition数量: %s\n", defaultIfNegative(int64(partitions)))
+	fmt.Printf("副本数量: %s\n", defaultIfNegative(int64(replicationFactor)))
+	if len(configs) > 0 {
+		keys := make([]string, 0, len(configs))
+		for key := range configs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Println("Topic配置:")
+		for _, key := range keys {
+			fmt.Printf("  %s=%s\n", key, configs[key])
+		}
+	}
+
+	if err := cluster.Admin().CreateTopic(topic, detail, validateOnly); err != nil {
+		return err
+	}
+
+	if validateOnly {
+		color.Green("✔校验通过，topic %s 可以创建(validate-only模式，未实际创建)", topic)
+	} else {
+		color.Green("✔创建topic %s 成功", topic)
+	}
+	return nil
+}
+
+func defaultIfNegative(n int64) string {
+	if n < 0 {
+		return "broker默认值"
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,89 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// MatchTopics 按精确名称、关键词或正则匹配topic，三者只取优先级最高的一个：名称 > 正则 > 关键词。
+// 关键词和正则不会匹配以"__"开头的内部topic，内部topic只能通过精确名称指定
+func MatchTopics(cluster cluster_tools.Cluster, name, keyword, pattern string) ([]string, error) {
+	if name == "" && keyword == "" && pattern == "" {
+		return nil, fmt.Errorf("必须指定topic名称、关键词或正则表达式")
+	}
+
+	client := cluster.Client()
+	if err := client.RefreshMetadata(); err != nil {
+		return nil, err
+	}
+	topics, err := client.Topics()
+	if err != nil {
+		return nil, err
+	}
+
+	var re *regexp.Regexp
+	if name == "" && pattern != "" {
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式无效: %v", err)
+		}
+	}
+
+	var matched []string
+	for _, topic := range topics {
+		switch {
+		case name != "":
+			if topic == name {
+				matched = append(matched, topic)
+			}
+		case strings.HasPrefix(topic, "__"):
+			continue
+		case re != nil:
+			if re.MatchString(topic) {
+				matched = append(matched, topic)
+			}
+		case strings.Contains(strings.ToLower(topic), strings.ToLower(keyword)):
+			matched = append(matched, topic)
+		}
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// PrintTopicsPreview 打印即将被操作的topic及其分区数和消息数
+func PrintTopicsPreview(cluster cluster_tools.Cluster, topics []string) {
+	var rows [][]string
+	for i, topic := range topics {
+		partitions, err := cluster.Client().Partitions(topic)
+		partitionCount := fmt.Sprintf("%d", len(partitions))
+		if err != nil {
+			partitionCount = "?"
+		}
+		messages := "?"
+		if count, err := TopicMessageCount(cluster, topic); err == nil {
+			messages = format_tools.FormatIntWithCommas(count)
+		}
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), topic, partitionCount, messages})
+	}
+	format_tools.PrintPrettyTable([]string{"ID", "TOPIC", "PARTITIONS", "MESSAGES"}, rows)
+}
+
+// DeleteTopics 逐个删除topic，返回删除失败的数量
+func DeleteTopics(cluster cluster_tools.Cluster, topics []string) int {
+	failed := 0
+	for _, topic := range topics {
+		if err := cluster.Admin().DeleteTopic(topic); err != nil {
+			cluster_tools.PrintError("✘删除topic %s 失败: %v", topic, err)
+			failed++
+			continue
+		}
+		color.Green("✔已删除topic %s", topic)
+	}
+	return failed
+}
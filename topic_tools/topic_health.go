@@ -0,0 +1,177 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// partitionProblem 一个异常分区及其问题列表
+type partitionProblem struct {
+	info     PartitionInfo
+	minISR   int
+	problems []string
+}
+
+// brokerHealth 按broker汇总的异常分区数量
+type brokerHealth struct {
+	underReplicated int
+	underMinISR     int
+	leaderless      int
+}
+
+// ClusterHealth 扫描所有topic的元数据，列出副本不足、没有leader和ISR少于min.insync.replicas的分区，
+// 并按broker汇总，返回异常分区数量
+func ClusterHealth(cluster cluster_tools.Cluster) (int, error) {
+	infos, err := DescribePartitions(cluster, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	topicSet := make(map[string]struct{})
+	for _, info := range infos {
+		topicSet[info.Topic] = struct{}{}
+	}
+	topics := make([]string, 0, len(topicSet))
+	for topic := range topicSet {
+		topics = append(topics, topic)
+	}
+	minISRs, err := topicMinISR(cluster, topics)
+	if err != nil {
+		return 0, fmt.Errorf("获取min.insync.replicas失败: %v", err)
+	}
+
+	var problems []partitionProblem
+	brokers := make(map[int32]*brokerHealth)
+	brokerStat := func(id int32) *brokerHealth {
+		if brokers[id] == nil {
+			brokers[id] = &brokerHealth{}
+		}
+		return brokers[id]
+	}
+
+	for _, info := range infos {
+		p := partitionProblem{info: info, minISR: minISRs[info.Topic]}
+		outOfSync := info.OutOfSync()
+		if info.Leaderless() {
+			p.problems = append(p.problems, "no leader")
+			// 没有leader时所有副本所在的broker都可能是原因
+			for _, id := range info.Replicas {
+				brokerStat(id).leaderless++
+			}
+		}
+		if info.UnderReplicated() {
+			p.problems = append(p.problems, "under-replicated")
+			for _, id := range outOfSync {
+				brokerStat(id).underReplicated++
+			}
+		}
+		if p.minISR > 0 && len(info.ISR) < p.minISR {
+			p.problems = append(p.problems, "under-min-isr")
+			for _, id := range outOfSync {
+				brokerStat(id).underMinISR++
+			}
+		}
+		if len(p.problems) > 0 {
+			problems = append(problems, p)
+		}
+	}
+
+	fmt.Printf("共检查 %d 个topic, %d 个分区\n", len(topics), len(infos))
+	if len(problems) == 0 {
+		color.Green("✔所有分区健康")
+		return 0, nil
+	}
+
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, p := range problems {
+		leader := fmt.Sprintf("%d", p.info.Leader)
+		if p.info.Leaderless() {
+			leader = "none"
+		}
+		if p.info.Leaderless() || (p.minISR > 0 && len(p.info.ISR) < p.minISR) {
+			rowColors[i] = color.New(color.FgRed)
+		} else {
+			rowColors[i] = color.New(color.FgYellow)
+		}
+		rows = append(rows, []string{
+			p.info.Topic,
+			fmt.Sprintf("%d", p.info.Partition),
+			leader,
+			formatBrokerIDs(p.info.Replicas),
+			formatBrokerIDs(p.info.ISR),
+			fmt.Sprintf("%d", p.minISR),
+			strings.Join(p.problems, ","),
+		})
+	}
+	fmt.Println("异常分区:")
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "LEADER", "REPLICAS", "ISR", "MIN-ISR", "PROBLEMS"}, rows, rowColors)
+
+	ids := make([]int32, 0, len(brokers))
+	for id := range brokers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var brokerRows [][]string
+	for _, id := range ids {
+		b := brokers[id]
+		brokerRows = append(brokerRows, []string{
+			fmt.Sprintf("%d", id),
+			fmt.Sprintf("%d", b.underReplicated),
+			fmt.Sprintf("%d", b.underMinISR),
+			fmt.Sprintf("%d", b.leaderless),
+		})
+	}
+	fmt.Println("按broker汇总(副本不在ISR中或没有leader的分区):")
+	format_tools.PrintPrettyTable([]string{"BROKER", "UNDER-REPLICATED", "UNDER-MIN-ISR", "LEADERLESS"}, brokerRows)
+
+	color.Red("发现 %d 个异常分区", len(problems))
+	return len(problems), nil
+}
+
+// topicMinISR 一次请求获取所有topic生效的min.insync.replicas
+func topicMinISR(cluster cluster_tools.Cluster, topics []string) (map[string]int, error) {
+	result := make(map[string]int, len(topics))
+	if len(topics) == 0 {
+		return result, nil
+	}
+
+	request := &sarama.DescribeConfigsRequest{}
+	for _, topic := range topics {
+		request.Resources = append(request.Resources, &sarama.ConfigResource{
+			Type:        sarama.TopicResource,
+			Name:        topic,
+			ConfigNames: []string{"min.insync.replicas"},
+		})
+	}
+
+	broker, err := cluster.Admin().Controller()
+	if err != nil {
+		return nil, err
+	}
+	response, err := broker.DescribeConfigs(request)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range response.Resources {
+		if resource.ErrorCode != 0 {
+			continue
+		}
+		for _, entry := range resource.Configs {
+			if entry.Name != "min.insync.replicas" {
+				continue
+			}
+			if n, err := strconv.Atoi(entry.Value); err == nil {
+				result[resource.Name] = n
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package topic_tools
+
+import (
+	"errors"
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"sort"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+// LeaderBalanceReport 基于分区元数据统计每个broker当前的leader数和应当承担的优先leader数，
+// 并列出leader不是优先副本的分区。返回leader不是优先副本的分区
+func LeaderBalanceReport(cluster cluster_tools.Cluster, topics []string) ([]PartitionInfo, error) {
+	infos, err := DescribePartitions(cluster, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	leaders := make(map[int32]int)
+	preferred := make(map[int32]int)
+	replicas := make(map[int32]int)
+	for _, broker := range cluster.Client().Brokers() {
+		leaders[broker.ID()] = 0
+	}
+	var notPreferred []PartitionInfo
+	for _, info := range infos {
+		if !info.Leaderless() {
+			leaders[info.Leader]++
+		}
+		if id := info.PreferredLeader(); id >= 0 {
+			preferred[id]++
+		}
+		for _, id := range info.Replicas {
+			replicas[id]++
+		}
+		if !info.Leaderless() && info.Leader != info.PreferredLeader() {
+			notPreferred = append(notPreferred, info)
+		}
+	}
+
+	var ids []int32
+	for id := range leaders {
+		ids = append(ids, id)
+	}
+	for id := range preferred {
+		if _, ok := leaders[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for i, id := range ids {
+		skew := leaders[id] - preferred[id]
+		if skew != 0 {
+			rowColors[i] = color.New(color.FgYellow)
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", id),
+			fmt.Sprintf("%d", leaders[id]),
+			fmt.Sprintf("%d", preferred[id]),
+			fmt.Sprintf("%+d", skew),
+			fmt.Sprintf("%d", replicas[id]),
+		})
+	}
+	fmt.Printf("共 %d 个分区的leader分布:\n", len(infos))
+	format_tools.PrintPrettyTableWithColors([]string{"BROKER", "LEADERS", "PREFERRED", "SKEW", "REPLICAS"}, rows, rowColors)
+
+	if len(notPreferred) == 0 {
+		color.Green("✔所有分区的leader都是优先副本")
+		return nil, nil
+	}
+
+	rows = nil
+	rowColors = make(map[int]*color.Color)
+	for i, info := range notPreferred {
+		inSync := "yes"
+		// 优先副本不在ISR中时无法选举为leader
+		if !containsBroker(info.ISR, info.PreferredLeader()) {
+			inSync = "no"
+			rowColors[i] = color.New(color.FgRed)
+		}
+		rows = append(rows, []string{
+			info.Topic,
+			fmt.Sprintf("%d", info.Partition),
+			fmt.Sprintf("%d", info.Leader),
+			fmt.Sprintf("%d", info.PreferredLeader()),
+			formatBrokerIDs(info.Replicas),
+			formatBrokerIDs(info.ISR),
+			inSync,
+		})
+	}
+	fmt.Println("leader不是优先副本的分区:")
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "LEADER", "PREFERRED", "REPLICAS", "ISR", "PREFERRED-IN-ISR"}, rows, rowColors)
+	color.Yellow("%d 个分区的leader不是优先副本，可使用-elect-leaders触发优先副本选举", len(notPreferred))
+	return notPreferred, nil
+}
+
+// containsBroker ids中是否包含id
+func containsBroker(ids []int32, id int32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// LeaderElectionCandidates 需要选举的分区：优先副本选举只选leader不是优先副本的分区，
+// unclean选举只选没有leader的分区
+func LeaderElectionCandidates(cluster cluster_tools.Cluster, topics []string, electionType sarama.ElectionType) ([]PartitionInfo, error) {
+	infos, err := DescribePartitions(cluster, topics)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []PartitionInfo
+	for _, info := range infos {
+		if electionType == sarama.UncleanElection {
+			if info.Leaderless() {
+				candidates = append(candidates, info)
+			}
+		} else if !info.Leaderless() && info.Leader != info.PreferredLeader() {
+			candidates = append(candidates, info)
+		}
+	}
+	return candidates, nil
+}
+
+// ElectLeaders 对partitions触发leader选举并打印每个分区的结果，返回失败的分区数
+func ElectLeaders(cluster cluster_tools.Cluster, electionType sarama.ElectionType, partitions []PartitionInfo) (int, error) {
+	// 低于2.4的协议版本没有选举类型字段，unclean选举会被当作优先副本选举
+	if electionType == sarama.UncleanElection {
+		if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "unclean选举"); err != nil {
+			return 0, err
+		}
+	}
+	request := make(map[string][]int32)
+	for _, info := range partitions {
+		request[info.Topic] = append(request[info.Topic], info.Partition)
+	}
+	results, err := cluster.Admin().ElectLeaders(electionType, request)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
+	for _, info := range partitions {
+		result := "OK"
+		if r := results[info.Topic][info.Partition]; r != nil && !errors.Is(r.ErrorCode, sarama.ErrNoError) && !errors.Is(r.ErrorCode, sarama.ErrElectionNotNeeded) {
+			result = r.ErrorCode.Error()
+			if r.ErrorMessage != nil && *r.ErrorMessage != "" {
+				result = *r.ErrorMessage
+			}
+			rowColors[len(rows)] = color.New(color.FgRed)
+			failed++
+		}
+		rows = append(rows, []string{info.Topic, fmt.Sprintf("%d", info.Partition), result})
+	}
+	format_tools.PrintPrettyTableWithColors([]string{"TOPIC", "PARTITION", "RESULT"}, rows, rowColors)
+	return failed, nil
+}
@@ -0,0 +1,146 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/consumer_tools"
+	"kafka_dog/format_tools"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+// RemapReport 增加分区后key被分配到不同分区的影响分析
+type RemapReport struct {
+	OldPartitions int32
+	NewPartitions int32
+	Messages      int
+	NullKeys      int
+	DistinctKeys  int
+	MovedKeys     int
+	// Mismatched 实际所在分区与默认分区器计算结果不一致的key数量，较多时说明生产者没有使用默认分区器
+	Mismatched int
+	// MovedByPartition 每个现有分区中会被移动到其他分区的key数量
+	MovedByPartition map[int32]int
+	KeysByPartition  map[int32]int
+}
+
+// AnalyzeKeyRemapping 按Java客户端默认的murmur2分区器计算采样key在新旧分区数下的分区，统计会改变分区的key
+func AnalyzeKeyRemapping(keysByPartition map[int32][][]byte, oldPartitions, newPartitions int32) RemapReport {
+	report := RemapReport{
+		OldPartitions:    oldPartitions,
+		NewPartitions:    newPartitions,
+		MovedByPartition: map[int32]int{},
+		KeysByPartition:  map[int32]int{},
+	}
+	seen := make(map[string]struct{})
+	for partition, keys := range keysByPartition {
+		for _, key := range keys {
+			report.Messages++
+			if key == nil {
+				report.NullKeys++
+				continue
+			}
+			if _, ok := seen[string(key)]; ok {
+				continue
+			}
+			seen[string(key)] = struct{}{}
+			report.DistinctKeys++
+			report.KeysByPartition[partition]++
+
+			oldPartition := DefaultPartition(key, oldPartitions)
+			if oldPartition != partition {
+				report.Mismatched++
+			}
+			if DefaultPartition(key, newPartitions) != oldPartition {
+				report.MovedKeys++
+				report.MovedByPartition[partition]++
+			}
+		}
+	}
+	return report
+}
+
+// PrintRemapReport 打印key重新映射的影响
+func PrintRemapReport(report RemapReport) {
+	fmt.Printf("分区数: %d -> %d\n", report.OldPartitions, report.NewPartitions)
+	fmt.Printf("采样消息数: %s, 其中key为空: %s, 不同的key: %s\n",
+		format_tools.FormatIntWithCommas(int64(report.Messages)),
+		format_tools.FormatIntWithCommas(int64(report.NullKeys)),
+		format_tools.FormatIntWithCommas(int64(report.DistinctKeys)))
+	if report.DistinctKeys == 0 {
+		color.Yellow("没有采样到非空key，key为空的消息不依赖分区顺序，增加分区不影响按key的顺序")
+		return
+	}
+
+	partitions := make([]int32, 0, len(report.KeysByPartition))
+	for partition := range report.KeysByPartition {
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	var rows [][]string
+	for _, partition := range partitions {
+		keys := report.KeysByPartition[partition]
+		moved := report.MovedByPartition[partition]
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", partition),
+			format_tools.FormatIntWithCommas(int64(keys)),
+			format_tools.FormatIntWithCommas(int64(moved)),
+			fmt.Sprintf("%.1f%%", percent(moved, keys)),
+		})
+	}
+	format_tools.PrintPrettyTable([]string{"PARTITION", "KEYS", "MOVED-KEYS", "MOVED"}, rows)
+
+	movedPercent := percent(report.MovedKeys, report.DistinctKeys)
+	msg := fmt.Sprintf("%d/%d 个key(%.1f%%)会被分配到新的分区，这些key在扩容前后的消息无法保证按key有序",
+		report.MovedKeys, report.DistinctKeys, movedPercent)
+	if report.MovedKeys > 0 {
+		color.Red(msg)
+	} else {
+		color.Green(msg)
+	}
+	if report.Mismatched > 0 {
+		color.Yellow("%d/%d 个key当前所在分区与murmur2默认分区器的计算结果不一致，生产者可能使用了自定义分区器，以上结果仅供参考",
+			report.Mismatched, report.DistinctKeys)
+	}
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+// PreviewAddPartitions 采样key并分析增加分区的影响，返回当前分区数
+func PreviewAddPartitions(cluster cluster_tools.Cluster, topic string, newPartitions int32, samplePerPartition int) (int32, error) {
+	partitions, err := cluster.Client().Partitions(topic)
+	if err != nil {
+		return 0, err
+	}
+	oldPartitions := int32(len(partitions))
+	if newPartitions <= oldPartitions {
+		return oldPartitions, fmt.Errorf("新的分区数(%d)必须大于当前分区数(%d)，Kafka不支持减少分区", newPartitions, oldPartitions)
+	}
+
+	fmt.Printf("正在从topic %s 的每个分区采样最多 %d 条消息的key...\n", topic, samplePerPartition)
+	keys, err := consumer_tools.SampleKeys(cluster, topic, samplePerPartition)
+	if err != nil {
+		return oldPartitions, err
+	}
+	PrintRemapReport(AnalyzeKeyRemapping(keys, oldPartitions, newPartitions))
+	return oldPartitions, nil
+}
+
+// AddPartitions 把topic的分区数增加到newPartitions
+func AddPartitions(cluster cluster_tools.Cluster, topic string, newPartitions int32, validateOnly bool) error {
+	if err := cluster.Admin().CreatePartitions(topic, newPartitions, nil, validateOnly); err != nil {
+		return err
+	}
+	if validateOnly {
+		color.Green("✔校验通过，topic %s 的分区数可以增加到 %d(validate-only模式，未实际修改)", topic, newPartitions)
+	} else {
+		color.Green("✔topic %s 的分区数已增加到 %d", topic, newPartitions)
+	}
+	return nil
+}
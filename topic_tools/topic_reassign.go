@@ -0,0 +1,344 @@
+package topic_tools
+
+import (
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/fatih/color"
+)
+
+const (
+	leaderThrottledRate       = "leader.replication.throttled.rate"
+	followerThrottledRate     = "follower.replication.throttled.rate"
+	leaderThrottledReplicas   = "leader.replication.throttled.replicas"
+	followerThrottledReplicas = "follower.replication.throttled.replicas"
+)
+
+// ListReassignments 获取topics中正在进行的分区重分配，topics为空时查询所有topic
+func ListReassignments(cluster cluster_tools.Cluster, topics []string) (map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus, error) {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "查询分区重分配"); err != nil {
+		return nil, err
+	}
+	client := cluster.Client()
+	if err := client.RefreshMetadata(topics...); err != nil {
+		return nil, err
+	}
+	if len(topics) == 0 {
+		var err error
+		if topics, err = client.Topics(); err != nil {
+			return nil, err
+		}
+	}
+
+	request := &sarama.ListPartitionReassignmentsRequest{TimeoutMs: 60000}
+	for _, topic := range topics {
+		partitions, err := client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("获取topic %s 的分区失败: %v", topic, err)
+		}
+		request.AddBlock(topic, partitions)
+	}
+
+	controller, err := cluster.Admin().Controller()
+	if err != nil {
+		return nil, err
+	}
+	response, err := controller.ListPartitionReassignments(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.ErrorCode != sarama.ErrNoError {
+		return nil, response.ErrorCode
+	}
+	return response.TopicStatus, nil
+}
+
+// countReassignments 正在重分配的分区数量
+func countReassignments(status map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus) int {
+	count := 0
+	for _, partitions := range status {
+		count += len(partitions)
+	}
+	return count
+}
+
+// PrintReassignmentStatus 打印正在进行的重分配
+func PrintReassignmentStatus(status map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus) {
+	var topics []string
+	for topic := range status {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	var rows [][]string
+	for _, topic := range topics {
+		var partitions []int32
+		for partition := range status[topic] {
+			partitions = append(partitions, partition)
+		}
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+		for _, partition := range partitions {
+			s := status[topic][partition]
+			rows = append(rows, []string{
+				topic,
+				fmt.Sprintf("%d", partition),
+				formatBrokerIDs(s.Replicas),
+				formatBrokerIDs(s.AddingReplicas),
+				formatBrokerIDs(s.RemovingReplicas),
+			})
+		}
+	}
+	format_tools.PrintPrettyTable([]string{"TOPIC", "PARTITION", "REPLICAS", "ADDING", "REMOVING"}, rows)
+}
+
+// ExecuteReassignment 提交重分配计划中发生变化的分区，throttle大于0时先设置副本同步限流(字节/秒)
+func ExecuteReassignment(cluster cluster_tools.Cluster, plan ReassignmentPlan, moves []ReassignmentMove, throttle int64) error {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "分区重分配"); err != nil {
+		return err
+	}
+	status, err := ListReassignments(cluster, plan.Topics())
+	if err != nil {
+		return fmt.Errorf("查询正在进行的重分配失败: %v", err)
+	}
+	if countReassignments(status) > 0 {
+		PrintReassignmentStatus(status)
+		return fmt.Errorf("以上分区已有正在进行的重分配，请等待完成或先使用-reassign-cancel取消")
+	}
+
+	if throttle > 0 {
+		if err := setReassignmentThrottle(cluster, moves, throttle); err != nil {
+			// 部分topic或broker可能已经设置成功
+			clearThrottleAfterFailure(cluster, plan.Topics())
+			return fmt.Errorf("设置限流失败: %v", err)
+		}
+		color.Green("✔已设置副本同步限流: %s 字节/秒", format_tools.FormatIntWithCommas(throttle))
+	}
+
+	if err := submitReassignment(cluster, plan, moves); err != nil {
+		if throttle > 0 {
+			clearThrottleAfterFailure(cluster, plan.Topics())
+		}
+		return err
+	}
+	color.Green("✔已提交 %d 个分区的重分配", len(moves))
+	return nil
+}
+
+// submitReassignment 只提交moves中的分区，与CancelReassignment一样直接发送给controller，
+// 提交后确认每个分区都在重分配或已经完成
+func submitReassignment(cluster cluster_tools.Cluster, plan ReassignmentPlan, moves []ReassignmentMove) error {
+	request := &sarama.AlterPartitionReassignmentsRequest{TimeoutMs: 60000}
+	for _, m := range moves {
+		request.AddBlock(m.Topic, m.Partition, m.Target)
+	}
+
+	controller, err := cluster.Admin().Controller()
+	if err != nil {
+		return fmt.Errorf("提交重分配失败: %v", err)
+	}
+	response, err := controller.AlterPartitionReassignments(request)
+	if err != nil {
+		return fmt.Errorf("提交重分配失败: %v", err)
+	}
+	if response.ErrorCode != sarama.ErrNoError {
+		return fmt.Errorf("提交重分配失败: %v", response.ErrorCode)
+	}
+
+	// 分区级别的错误码sarama没有导出，通过查询重分配状态和当前副本确认提交结果
+	status, err := ListReassignments(cluster, plan.Topics())
+	if err != nil {
+		return fmt.Errorf("确认提交结果失败: %v", err)
+	}
+	pending, err := PlanMoves(cluster, plan)
+	if err != nil {
+		return fmt.Errorf("确认提交结果失败: %v", err)
+	}
+	var rejected []ReassignmentMove
+	for _, m := range pending {
+		if status[m.Topic][m.Partition] == nil {
+			rejected = append(rejected, m)
+		}
+	}
+	if len(rejected) > 0 {
+		color.Red("以下分区的重分配未被controller接受:")
+		PrintReassignmentMoves(rejected)
+		return fmt.Errorf("%d 个分区的重分配提交失败", len(rejected))
+	}
+	return nil
+}
+
+// clearThrottleAfterFailure 提交失败后移除已设置的限流，移除失败时提示手动处理
+func clearThrottleAfterFailure(cluster cluster_tools.Cluster, topics []string) {
+	if err := ClearReassignmentThrottle(cluster, topics); err != nil {
+		cluster_tools.PrintError("%v", err)
+	}
+}
+
+// WaitReassignment 每隔interval查询一次计划中分区的重分配进度，全部完成后校验副本并移除限流。
+// 按Ctrl+C只停止跟踪，重分配会在集群中继续进行
+func WaitReassignment(cluster cluster_tools.Cluster, plan ReassignmentPlan, interval time.Duration) error {
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigchan)
+
+	total := len(plan.Partitions)
+	for {
+		status, err := ListReassignments(cluster, plan.Topics())
+		if err != nil {
+			return fmt.Errorf("查询重分配进度失败: %v", err)
+		}
+		status = plan.FilterStatus(status)
+		remaining := countReassignments(status)
+		fmt.Printf("[%s] 重分配进度: %d/%d 个分区已完成\n", time.Now().Format("15:04:05"), total-remaining, total)
+		if remaining == 0 {
+			break
+		}
+		if remaining <= 10 {
+			PrintReassignmentStatus(status)
+		}
+
+		select {
+		case <-sigchan:
+			color.Yellow("已停止跟踪，重分配仍在集群中进行，可使用-reassign-status继续查看进度，完成后会移除限流")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+
+	moves, err := PlanMoves(cluster, plan)
+	if err != nil {
+		return err
+	}
+	if len(moves) > 0 {
+		color.Yellow("以下分区的副本与计划不一致(可能被取消或有其他重分配):")
+		PrintReassignmentMoves(moves)
+	} else {
+		color.Green("✔重分配已完成，所有分区的副本与计划一致")
+	}
+	return ClearReassignmentThrottle(cluster, plan.Topics())
+}
+
+// setReassignmentThrottle 在迁移涉及的broker上设置同步速率限制，
+// 并在topic上标记需要限流的副本：当前副本作为leader端限流，新增副本作为follower端限流
+func setReassignmentThrottle(cluster cluster_tools.Cluster, moves []ReassignmentMove, throttle int64) error {
+	leaderReplicas := make(map[string][]string)
+	followerReplicas := make(map[string][]string)
+	brokers := make(map[int32]bool)
+	for _, m := range moves {
+		for _, id := range m.Current {
+			leaderReplicas[m.Topic] = append(leaderReplicas[m.Topic], fmt.Sprintf("%d:%d", m.Partition, id))
+			brokers[id] = true
+		}
+		for _, id := range m.Adding() {
+			followerReplicas[m.Topic] = append(followerReplicas[m.Topic], fmt.Sprintf("%d:%d", m.Partition, id))
+		}
+		for _, id := range m.Target {
+			brokers[id] = true
+		}
+	}
+
+	admin := cluster.Admin()
+	for topic, replicas := range leaderReplicas {
+		set := map[string]string{leaderThrottledReplicas: strings.Join(replicas, ",")}
+		if len(followerReplicas[topic]) > 0 {
+			set[followerThrottledReplicas] = strings.Join(followerReplicas[topic], ",")
+		}
+		entries := cluster_tools.IncrementalConfigEntries(set, nil, nil)
+		if err := admin.IncrementalAlterConfig(sarama.TopicResource, topic, entries, false); err != nil {
+			return fmt.Errorf("topic %s: %v", topic, err)
+		}
+	}
+
+	rate := fmt.Sprintf("%d", throttle)
+	for id := range brokers {
+		entries := cluster_tools.IncrementalConfigEntries(map[string]string{leaderThrottledRate: rate, followerThrottledRate: rate}, nil, nil)
+		if err := admin.IncrementalAlterConfig(sarama.BrokerResource, fmt.Sprintf("%d", id), entries, false); err != nil {
+			return fmt.Errorf("broker %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// ClearReassignmentThrottle 移除topics上的限流副本标记和所有broker上的同步速率限制，
+// 与kafka-reassign-partitions.sh --verify的行为一致
+func ClearReassignmentThrottle(cluster cluster_tools.Cluster, topics []string) error {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_3_0_0, "移除重分配限流"); err != nil {
+		return err
+	}
+	admin := cluster.Admin()
+	failed := 0
+	for _, topic := range topics {
+		entries := cluster_tools.IncrementalConfigEntries(nil, []string{leaderThrottledReplicas, followerThrottledReplicas}, nil)
+		if err := admin.IncrementalAlterConfig(sarama.TopicResource, topic, entries, false); err != nil {
+			cluster_tools.PrintError("移除topic %s 的限流失败: %v", topic, err)
+			failed++
+		}
+	}
+	for _, broker := range cluster.Client().Brokers() {
+		entries := cluster_tools.IncrementalConfigEntries(nil, []string{leaderThrottledRate, followerThrottledRate}, nil)
+		if err := admin.IncrementalAlterConfig(sarama.BrokerResource, fmt.Sprintf("%d", broker.ID()), entries, false); err != nil {
+			cluster_tools.PrintError("移除broker %d 的限流失败: %v", broker.ID(), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 处限流移除失败，请稍后使用-reassign-status重试", failed)
+	}
+	color.Green("✔已移除重分配限流")
+	return nil
+}
+
+// CancelReassignment 取消status中正在进行的重分配，分区会回到重分配前的副本列表
+func CancelReassignment(cluster cluster_tools.Cluster, status map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus) error {
+	if err := cluster_tools.RequireVersion(cluster, sarama.V2_4_0_0, "取消分区重分配"); err != nil {
+		return err
+	}
+	request := &sarama.AlterPartitionReassignmentsRequest{TimeoutMs: 60000}
+	var topics []string
+	for topic, partitions := range status {
+		topics = append(topics, topic)
+		for partition := range partitions {
+			// 副本列表为空表示取消该分区的重分配
+			request.AddBlock(topic, partition, nil)
+		}
+	}
+	sort.Strings(topics)
+
+	controller, err := cluster.Admin().Controller()
+	if err != nil {
+		return err
+	}
+	response, err := controller.AlterPartitionReassignments(request)
+	if err != nil {
+		return err
+	}
+	if response.ErrorCode != sarama.ErrNoError {
+		return response.ErrorCode
+	}
+
+	remaining, err := ListReassignments(cluster, topics)
+	if err != nil {
+		return fmt.Errorf("确认取消结果失败: %v", err)
+	}
+	stillRunning := 0
+	for topic, partitions := range remaining {
+		for partition := range partitions {
+			if status[topic][partition] != nil {
+				stillRunning++
+			}
+		}
+	}
+	if stillRunning > 0 {
+		return fmt.Errorf("%d 个分区的重分配未能取消", stillRunning)
+	}
+	color.Green("✔已取消 %d 个分区的重分配", countReassignments(status))
+	return ClearReassignmentThrottle(cluster, topics)
+}
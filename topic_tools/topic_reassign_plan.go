@@ -0,0 +1,339 @@
+package topic_tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"kafka_dog/cluster_tools"
+	"kafka_dog/format_tools"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// ReassignmentPlan 分区重分配计划，格式与kafka-reassign-partitions.sh的JSON文件兼容
+type ReassignmentPlan struct {
+	Version    int                     `json:"version"`
+	Partitions []PartitionReassignment `json:"partitions"`
+}
+
+// PartitionReassignment 一个分区的目标副本列表，第一个副本为优先leader
+type PartitionReassignment struct {
+	Topic     string   `json:"topic"`
+	Partition int32    `json:"partition"`
+	Replicas  []int32  `json:"replicas"`
+	LogDirs   []string `json:"log_dirs,omitempty"`
+}
+
+// Topics 计划中涉及的topic，按名称排序
+func (p ReassignmentPlan) Topics() []string {
+	seen := make(map[string]struct{})
+	var topics []string
+	for _, r := range p.Partitions {
+		if _, ok := seen[r.Topic]; !ok {
+			seen[r.Topic] = struct{}{}
+			topics = append(topics, r.Topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// FilterStatus 只保留计划中的分区的重分配状态
+func (p ReassignmentPlan) FilterStatus(status map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus) map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus {
+	inPlan := make(map[string]bool, len(p.Partitions))
+	for _, r := range p.Partitions {
+		inPlan[fmt.Sprintf("%s-%d", r.Topic, r.Partition)] = true
+	}
+	filtered := make(map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus)
+	for topic, partitions := range status {
+		for partition, s := range partitions {
+			if !inPlan[fmt.Sprintf("%s-%d", topic, partition)] {
+				continue
+			}
+			if filtered[topic] == nil {
+				filtered[topic] = make(map[int32]*sarama.PartitionReplicaReassignmentsStatus)
+			}
+			filtered[topic][partition] = s
+		}
+	}
+	return filtered
+}
+
+// LoadReassignmentPlan 读取并校验重分配计划文件
+func LoadReassignmentPlan(path string) (ReassignmentPlan, error) {
+	var plan ReassignmentPlan
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return plan, fmt.Errorf("读取重分配计划失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("解析重分配计划 %s 失败: %v", path, err)
+	}
+	if len(plan.Partitions) == 0 {
+		return plan, fmt.Errorf("重分配计划 %s 中没有分区", path)
+	}
+	seen := make(map[string]bool)
+	for _, r := range plan.Partitions {
+		key := fmt.Sprintf("%s-%d", r.Topic, r.Partition)
+		if seen[key] {
+			return plan, fmt.Errorf("重分配计划中分区 %s 重复", key)
+		}
+		seen[key] = true
+		if len(r.Replicas) == 0 {
+			return plan, fmt.Errorf("分区 %s 的副本列表为空", key)
+		}
+		brokers := make(map[int32]bool)
+		for _, id := range r.Replicas {
+			if brokers[id] {
+				return plan, fmt.Errorf("分区 %s 的副本列表中broker %d 重复", key, id)
+			}
+			brokers[id] = true
+		}
+	}
+	return plan, nil
+}
+
+// SaveReassignmentPlan 把重分配计划写入JSON文件
+func SaveReassignmentPlan(path string, plan ReassignmentPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// RollbackPlanPath 回滚文件路径，plan.json对应plan.rollback.json
+func RollbackPlanPath(path string) string {
+	return strings.TrimSuffix(path, ".json") + ".rollback.json"
+}
+
+// ParseBrokerIDs 解析"1,2,3"格式的broker ID列表
+func ParseBrokerIDs(s string) ([]int32, error) {
+	var ids []int32
+	seen := make(map[int32]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("broker ID %q 无效", part)
+		}
+		if !seen[int32(id)] {
+			seen[int32(id)] = true
+			ids = append(ids, int32(id))
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("必须指定至少一个目标broker ID")
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// GenerateReassignment 为topics生成把副本均衡分布到targetBrokers上的计划，副本数保持不变。
+// 已经在目标broker上的副本尽量保留以减少数据迁移，不足的副本补到副本最少的broker上，
+// 并优先选择分区中还没有使用的机架。返回目标计划和当前分配(用于回滚)
+func GenerateReassignment(cluster cluster_tools.Cluster, topics []string, targetBrokers []int32) (ReassignmentPlan, ReassignmentPlan, error) {
+	target := ReassignmentPlan{Version: 1}
+	current := ReassignmentPlan{Version: 1}
+
+	racks := make(map[int32]string)
+	for _, broker := range cluster.Client().Brokers() {
+		racks[broker.ID()] = broker.Rack()
+	}
+	for _, id := range targetBrokers {
+		if _, ok := racks[id]; !ok {
+			return target, current, fmt.Errorf("broker %d 不在集群中", id)
+		}
+	}
+
+	infos, err := DescribePartitions(cluster, topics)
+	if err != nil {
+		return target, current, err
+	}
+
+	total := 0
+	for _, info := range infos {
+		if len(info.Replicas) > len(targetBrokers) {
+			return target, current, fmt.Errorf("%s-%d 的副本数为%d，超过了目标broker数量%d", info.Topic, info.Partition, len(info.Replicas), len(targetBrokers))
+		}
+		total += len(info.Replicas)
+	}
+	// 每个broker最多承担的副本数，保留的副本超过上限时迁走
+	maxPerBroker := (total + len(targetBrokers) - 1) / len(targetBrokers)
+
+	isTarget := make(map[int32]bool, len(targetBrokers))
+	for _, id := range targetBrokers {
+		isTarget[id] = true
+	}
+	load := make(map[int32]int)
+	leaderLoad := make(map[int32]int)
+
+	for _, info := range infos {
+		current.Partitions = append(current.Partitions, PartitionReassignment{Topic: info.Topic, Partition: info.Partition, Replicas: info.Replicas})
+
+		var replicas []int32
+		for _, id := range info.Replicas {
+			if isTarget[id] && load[id] < maxPerBroker {
+				replicas = append(replicas, id)
+				load[id]++
+			}
+		}
+		for len(replicas) < len(info.Replicas) {
+			id := pickBroker(targetBrokers, replicas, racks, load)
+			replicas = append(replicas, id)
+			load[id]++
+		}
+		// 原来的优先leader被迁走时，让leader最少的副本成为新的优先leader
+		if replicas[0] != info.PreferredLeader() {
+			best := 0
+			for i, id := range replicas {
+				if leaderLoad[id] < leaderLoad[replicas[best]] {
+					best = i
+				}
+			}
+			replicas[0], replicas[best] = replicas[best], replicas[0]
+		}
+		leaderLoad[replicas[0]]++
+
+		target.Partitions = append(target.Partitions, PartitionReassignment{Topic: info.Topic, Partition: info.Partition, Replicas: replicas})
+	}
+	return target, current, nil
+}
+
+// pickBroker 选择不在replicas中的broker：优先未使用的机架，其次副本数最少，最后ID最小
+func pickBroker(targetBrokers, replicas []int32, racks map[int32]string, load map[int32]int) int32 {
+	used := make(map[int32]bool, len(replicas))
+	usedRacks := make(map[string]bool)
+	for _, id := range replicas {
+		used[id] = true
+		if racks[id] != "" {
+			usedRacks[racks[id]] = true
+		}
+	}
+	best := int32(-1)
+	for _, id := range targetBrokers {
+		if used[id] {
+			continue
+		}
+		if best < 0 {
+			best = id
+			continue
+		}
+		rackUsed, bestRackUsed := usedRacks[racks[id]], usedRacks[racks[best]]
+		if rackUsed != bestRackUsed {
+			if !rackUsed {
+				best = id
+			}
+			continue
+		}
+		if load[id] < load[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+// ReassignmentMove 计划中副本发生变化的分区
+type ReassignmentMove struct {
+	Topic     string
+	Partition int32
+	Current   []int32
+	Target    []int32
+}
+
+// Adding 需要新增的副本
+func (m ReassignmentMove) Adding() []int32 {
+	return brokerDiff(m.Target, m.Current)
+}
+
+// Removing 需要移除的副本
+func (m ReassignmentMove) Removing() []int32 {
+	return brokerDiff(m.Current, m.Target)
+}
+
+// brokerDiff 在a中但不在b中的broker
+func brokerDiff(a, b []int32) []int32 {
+	in := make(map[int32]bool, len(b))
+	for _, id := range b {
+		in[id] = true
+	}
+	var diff []int32
+	for _, id := range a {
+		if !in[id] {
+			diff = append(diff, id)
+		}
+	}
+	return diff
+}
+
+// PlanMoves 比较计划和当前分配，返回副本列表(包括顺序)发生变化的分区
+func PlanMoves(cluster cluster_tools.Cluster, plan ReassignmentPlan) ([]ReassignmentMove, error) {
+	infos, err := DescribePartitions(cluster, plan.Topics())
+	if err != nil {
+		return nil, err
+	}
+	currentReplicas := make(map[string][]int32, len(infos))
+	for _, info := range infos {
+		currentReplicas[fmt.Sprintf("%s-%d", info.Topic, info.Partition)] = info.Replicas
+	}
+
+	var moves []ReassignmentMove
+	for _, r := range plan.Partitions {
+		current, ok := currentReplicas[fmt.Sprintf("%s-%d", r.Topic, r.Partition)]
+		if !ok {
+			return nil, fmt.Errorf("分区 %s-%d 不存在", r.Topic, r.Partition)
+		}
+		if formatBrokerIDs(current) == formatBrokerIDs(r.Replicas) {
+			continue
+		}
+		moves = append(moves, ReassignmentMove{Topic: r.Topic, Partition: r.Partition, Current: current, Target: r.Replicas})
+	}
+	return moves, nil
+}
+
+// PrintReassignmentMoves 打印发生变化的分区以及每个broker的副本数变化
+func PrintReassignmentMoves(moves []ReassignmentMove) {
+	var rows [][]string
+	before := make(map[int32]int)
+	after := make(map[int32]int)
+	for _, m := range moves {
+		rows = append(rows, []string{
+			m.Topic,
+			fmt.Sprintf("%d", m.Partition),
+			formatBrokerIDs(m.Current),
+			formatBrokerIDs(m.Target),
+			formatBrokerIDs(m.Adding()),
+			formatBrokerIDs(m.Removing()),
+		})
+		for _, id := range m.Current {
+			before[id]++
+		}
+		for _, id := range m.Target {
+			after[id]++
+		}
+	}
+	format_tools.PrintPrettyTable([]string{"TOPIC", "PARTITION", "CURRENT", "TARGET", "ADDING", "REMOVING"}, rows)
+
+	var ids []int32
+	for id := range before {
+		ids = append(ids, id)
+	}
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var brokerRows [][]string
+	for _, id := range ids {
+		brokerRows = append(brokerRows, []string{fmt.Sprintf("%d", id), fmt.Sprintf("%d", before[id]), fmt.Sprintf("%d", after[id])})
+	}
+	fmt.Println("变化分区的副本在各broker上的数量:")
+	format_tools.PrintPrettyTable([]string{"BROKER", "BEFORE", "AFTER"}, brokerRows)
+}
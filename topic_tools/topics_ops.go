@@ -2,19 +2,17 @@ package topic_tools
 
 import (
 	"fmt"
+	"kafka_dog/cluster_tools"
 	"kafka_dog/format_tools"
 	"log"
 	"strings"
 
 	"github.com/IBM/sarama"
+	"github.com/fatih/color"
 )
 
-func ShowTopics(brokers []string, config *sarama.Config, keyword string) {
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		log.Fatalf("Error creating Kafka client: %v", err)
-	}
-	defer client.Close()
+func ShowTopics(cluster cluster_tools.Cluster, keyword string) {
+	client := cluster.Client()
 
 	topics, err := client.Topics()
 	if err != nil {
@@ -33,12 +31,8 @@ func ShowTopics(brokers []string, config *sarama.Config, keyword string) {
 	}
 }
 
-func ShowTopicsReturnMap(brokers []string, config *sarama.Config, keyword string) map[int]string {
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		log.Fatalf("Error creating Kafka client: %v", err)
-	}
-	defer client.Close()
+func ShowTopicsReturnMap(cluster cluster_tools.Cluster, keyword string) map[int]string {
+	client := cluster.Client()
 
 	topics, err := client.Topics()
 	if err != nil {
@@ -61,42 +55,98 @@ func ShowTopicsReturnMap(brokers []string, config *sarama.Config, keyword string
 	return topicMap
 }
 
-func TopicDetail(brokers []string, config *sarama.Config, topic string) {
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		fmt.Printf("Error creating Kafka client: %v", err)
-	}
-	defer client.Close()
+func TopicDetail(cluster cluster_tools.Cluster, topic string) {
+	client := cluster.Client()
 
-	partitions, err := client.Partitions(topic)
+	infos, err := DescribePartitions(cluster, []string{topic})
 	if err != nil {
-		fmt.Printf("Error fetching partitions for topic %s: %v", topic, err)
+		cluster_tools.PrintError("Error fetching partitions for topic %s: %v", topic, err)
+		return
 	}
 
 	fmt.Printf("Topic名称: %s\n", topic)
-	fmt.Printf("Partition数量: %d\n", len(partitions))
+	fmt.Printf("Partition数量: %d\n", len(infos))
 
+	var rows [][]string
+	rowColors := make(map[int]*color.Color)
 	totalMessages := int64(0)
-	for _, partition := range partitions {
-		oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+	underReplicated := 0
+	for _, info := range infos {
+		oldestFormatted, newestFormatted, countFormatted := "?", "?", "?"
+		oldest, newest, err := partitionOffsets(client, topic, info.Partition)
 		if err != nil {
-			fmt.Printf("Error getting oldest offset for partition %d: %v", partition, err)
-			continue
+			cluster_tools.PrintError("Error getting offsets for partition %d: %v", info.Partition, err)
+		} else {
+			count := newest - oldest
+			totalMessages += count
+
+			newestFormatted = format_tools.FormatIntWithCommas(newest)
+			oldestFormatted = format_tools.FormatIntWithCommas(oldest)
+			countFormatted = format_tools.FormatIntWithCommas(count)
 		}
-		newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
-		if err != nil {
-			fmt.Printf("Error getting newest offset for partition %d: %v", partition, err)
-			continue
+
+		leader := fmt.Sprintf("%d", info.Leader)
+		if info.Leaderless() {
+			leader = "none"
+		}
+		// 没有leader的分区标红，ISR不完整的分区标黄
+		if info.Leaderless() || len(info.Offline) > 0 {
+			rowColors[len(rows)] = color.New(color.FgRed)
+		} else if info.UnderReplicated() {
+			rowColors[len(rows)] = color.New(color.FgYellow)
+		}
+		if info.UnderReplicated() {
+			underReplicated++
 		}
-		count := newest - oldest
-		totalMessages += count
 
-		newestFormatted := format_tools.FormatIntWithCommas(newest)
-		oldestFormatted := format_tools.FormatIntWithCommas(oldest)
-		countFormatted := format_tools.FormatIntWithCommas(count)
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", info.Partition),
+			leader,
+			formatBrokerIDs(info.Replicas),
+			formatBrokerIDs(info.ISR),
+			formatBrokerIDs(info.Offline),
+			oldestFormatted,
+			newestFormatted,
+			countFormatted,
+		})
+	}
+	headers := []string{"PARTITION", "LEADER", "REPLICAS", "ISR", "OFFLINE", "OLDEST-OFFSET", "NEWEST-OFFSET", "MESSAGES"}
+	format_tools.PrintPrettyTableWithColors(headers, rows, rowColors)
 
-		fmt.Printf("  Partition %d: oldest offset = %s | newest offset = %s | message count = %s\n", partition, oldestFormatted, newestFormatted, countFormatted)
+	if underReplicated > 0 {
+		color.Yellow("%d 个分区副本不足(ISR少于副本数)", underReplicated)
 	}
 	totalMessagesFormatted := format_tools.FormatIntWithCommas(totalMessages)
 	fmt.Printf("Topic'%s'中总消息数量: %s\n", topic, totalMessagesFormatted)
 }
+
+// partitionOffsets 返回分区最早和最新的offset，两者之差即为分区中的消息数
+func partitionOffsets(client sarama.Client, topic string, partition int32) (oldest, newest int64, err error) {
+	oldest, err = client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, err
+	}
+	newest, err = client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, err
+	}
+	return oldest, newest, nil
+}
+
+// TopicMessageCount 按TopicDetail相同的方式统计topic中的消息总数
+func TopicMessageCount(cluster cluster_tools.Cluster, topic string) (int64, error) {
+	client := cluster.Client()
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return 0, err
+	}
+	total := int64(0)
+	for _, partition := range partitions {
+		oldest, newest, err := partitionOffsets(client, topic, partition)
+		if err != nil {
+			return 0, err
+		}
+		total += newest - oldest
+	}
+	return total, nil
+}